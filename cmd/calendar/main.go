@@ -1,16 +1,48 @@
 package main
 
 import (
+	"calendar/internal/acl"
+	"calendar/internal/apikey"
+	"calendar/internal/auth"
+	"calendar/internal/changefeed"
 	"calendar/internal/config"
 	"calendar/internal/event"
+	"calendar/internal/health"
+	jwtauth "calendar/internal/infrastructure/auth/jwt"
+	gql "calendar/internal/infrastructure/graphql"
+	grpcserver "calendar/internal/infrastructure/grpc"
 	"calendar/internal/infrastructure/http/handlers"
 	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/openapi"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/infrastructure/metrics"
+	"calendar/internal/infrastructure/storage/file"
 	"calendar/internal/infrastructure/storage/in_memory"
+	"calendar/internal/lifecycle"
+	"calendar/internal/preferences"
+	"calendar/internal/ratelimit"
+	"calendar/internal/reminder"
+	"calendar/internal/scheduling"
+	"calendar/internal/sharelink"
+	"calendar/internal/tenant"
+	"calendar/internal/tenantadmin"
+	"calendar/internal/user"
+	"calendar/internal/webhook"
 	"calendar/pkg/sl_logger/sl"
+	"context"
 	"errors"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
@@ -26,74 +58,422 @@ func main() {
 	log := setupLogger(cfg.Env)
 	log = log.With(slog.String("env", cfg.Env))
 
-	storage := inmem.New()
-	service := event.NewService(storage)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	storage := metrics.NewEventStorage(inmem.New(), registry)
+	calendars := acl.NewService(inmem.NewACL())
+	service := event.NewService(storage, calendars, tenantQuotas(cfg))
+	profiles := preferences.NewService(inmem.NewPreferences())
+	planner := scheduling.NewService(service, profiles)
+	shareLinks := sharelink.NewService(inmem.NewShareLinks(), service, calendars)
+
+	// компоненты останавливаются в обратном порядке: серверы, воркеры,
+	// хранилища
+	components := lifecycle.New(log)
+
+	ledger := mustReminderLedger(log, cfg)
+	if c, ok := ledger.(io.Closer); ok {
+		components.Add(lifecycle.Closer("reminder ledger", c))
+	}
+	reminders := reminder.NewScheduler(log, reminder.NewLogNotifier(log), ledger)
+	service.Subscribe(reminders)
+	// напоминания нужны всем организациям, а не только организации по умолчанию
+	upcoming, err := service.ListAllByRange(context.Background(), time.Now(), time.Now().AddDate(10, 0, 0))
+	if err != nil {
+		log.Error("failed to load upcoming events", sl.Err(err))
+	}
+	reminders.Load(upcoming)
+	components.Add(lifecycle.Worker("reminder scheduler", reminders.Run))
+
+	webhookStorage := inmem.NewWebhooks()
+	webhookGuard := webhook.Guard{AllowPrivate: cfg.Webhooks.AllowPrivateHosts}
+	webhooks := webhook.NewService(webhookStorage, webhookGuard)
+	dispatcher := webhook.NewDispatcher(log, webhookStorage, service, webhook.Options{
+		Workers:     cfg.Webhooks.Workers,
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BaseBackoff: cfg.Webhooks.BaseBackoff,
+		MaxBackoff:  cfg.Webhooks.MaxBackoff,
+		Client:      webhookGuard.Client(cfg.Webhooks.DeliveryTimeout),
+	})
+	service.Subscribe(dispatcher)
+	components.Add(lifecycle.Worker("webhook dispatcher", dispatcher.Run))
+
+	feed := changefeed.NewLog(cfg.ChangeLogSize)
+	service.Subscribe(feed)
+	service.Subscribe(metrics.NewEvents(registry))
+
+	spec := openapi.MustNew(apiInfo, apiSecuritySchemes, apiOperations())
+	mux := openapi.NewMux()
+
+	apiKeys := apikey.NewService(inmem.NewAPIKeys())
 
-	mux := http.NewServeMux()
+	userStorage := inmem.NewUsers()
+	users := user.NewService(userStorage, userStorage, sessionIssuer(log, cfg), cfg.Auth.SessionTTL)
+
+	tenants := tenantadmin.NewService(tenantadmin.Services{
+		Events:     service,
+		ACL:        calendars,
+		Webhooks:   webhooks,
+		ShareLinks: shareLinks,
+		Profiles:   profiles,
+		Users:      users,
+		APIKeys:    apiKeys,
+	})
+
+	verifiers, anonymous := mustVerifiers(log, cfg, apiKeys, users)
+	rateLimiter := inmem.NewRateLimiter(cfg.RateLimit.IdleTTL)
+	// до аутентификации запросы считаются по адресу в отдельных корзинах,
+	// чтобы не делить их с анонимными маршрутами
+	preAuthLimiter := inmem.NewRateLimiter(cfg.RateLimit.IdleTTL)
+	preAuth := middleware.RateLimit(log, preAuthLimiter, middleware.RateLimitPolicy{
+		KeyBy:   ratelimit.KeyByIP,
+		Default: preAuthLimit(cfg),
+	}, response.Error)
+	authenticate := middleware.Authenticate(log, verifiers, response.Error)
+	if anonymous {
+		authenticate = middleware.Optional(authenticate)
+	}
+	authn := func(next http.Handler) http.Handler { return preAuth(authenticate(next)) }
+	canWrite := middleware.RequireScope(auth.ScopeWrite, response.Error)
+	isAdmin := middleware.RequireScope(auth.ScopeAdmin, response.Error)
+	readiness := readinessChecks(cfg, storage, components)
+	policy := mustRateLimitPolicy(log, cfg)
+	limit := middleware.RateLimit(log, rateLimiter, policy, response.Error)
 
 	// root := middleware.NewMWLogger(log)(middleware.RequestID(mux))
 	mux.Handle("/create_event",
 		middleware.NewMWLogger(log)(
 			middleware.RequestID(
-				http.HandlerFunc(handlers.NewAddEventHandler(log, service)),
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewAddEventHandler(log, service))))),
 			),
 		),
 	)
 	mux.Handle("/events_for_day",
 		middleware.NewMWLogger(log)(
 			middleware.RequestID(
-				http.HandlerFunc(handlers.NewEventsForDayHandler(log, service)),
+				authn(limit(http.HandlerFunc(handlers.NewEventsForDayHandler(log, service, profiles)))),
 			),
 		),
 	)
 	mux.Handle("/events_for_month",
 		middleware.NewMWLogger(log)(
 			middleware.RequestID(
-				http.HandlerFunc(handlers.NewEventsForMonthHandler(log, service)),
+				authn(limit(http.HandlerFunc(handlers.NewEventsForMonthHandler(log, service, profiles)))),
 			),
 		),
 	)
 	mux.Handle("/events_for_week",
 		middleware.NewMWLogger(log)(
 			middleware.RequestID(
-				http.HandlerFunc(handlers.NewEventsForWeekHandler(log, service)),
+				authn(limit(http.HandlerFunc(handlers.NewEventsForWeekHandler(log, service, profiles)))),
 			),
 		),
 	)
 	mux.Handle("/delete_event",
 		middleware.NewMWLogger(log)(
 			middleware.RequestID(
-				http.HandlerFunc(handlers.NewDeleteEventHandler(log, service)),
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewDeleteEventHandler(log, service))))),
 			),
 		),
 	)
 	mux.Handle("/update_event",
 		middleware.NewMWLogger(log)(
 			middleware.RequestID(
-				http.HandlerFunc(handlers.NewUpdateEventHandler(log, service)),
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewUpdateEventHandler(log, service))))),
+			),
+		),
+	)
+	mux.Handle("/freebusy",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewFreeBusyHandler(log, service)))),
+			),
+		),
+	)
+	mux.Handle("/suggest_slots",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewSuggestSlotsHandler(log, planner)))),
+			),
+		),
+	)
+	mux.Handle("/create_preferences",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewAddPreferencesHandler(log, profiles))))),
+			),
+		),
+	)
+	mux.Handle("/update_preferences",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewUpdatePreferencesHandler(log, profiles))))),
+			),
+		),
+	)
+	mux.Handle("/delete_preferences",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewDeletePreferencesHandler(log, profiles))))),
+			),
+		),
+	)
+	mux.Handle("/preferences",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewGetPreferencesHandler(log, profiles)))),
+			),
+		),
+	)
+	mux.Handle("/share_calendar",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewShareCalendarHandler(log, calendars))))),
+			),
+		),
+	)
+	mux.Handle("/unshare_calendar",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewUnshareCalendarHandler(log, calendars))))),
+			),
+		),
+	)
+	mux.Handle("/calendar_acl",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewCalendarACLHandler(log, calendars)))),
+			),
+		),
+	)
+	mux.Handle("/create_share_link",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewCreateShareLinkHandler(log, shareLinks))))),
+			),
+		),
+	)
+	mux.Handle("/share_links",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewListShareLinksHandler(log, shareLinks)))),
+			),
+		),
+	)
+	mux.Handle("/revoke_share_link",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(canWrite(http.HandlerFunc(handlers.NewRevokeShareLinkHandler(log, shareLinks))))),
+			),
+		),
+	)
+	mux.Handle("/shared",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				limit(http.HandlerFunc(handlers.NewSharedEventsHandler(log, shareLinks))),
+			),
+		),
+	)
+	mux.Handle("/create_webhook",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewAddWebhookHandler(log, webhooks))))),
+			),
+		),
+	)
+	mux.Handle("/delete_webhook",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewDeleteWebhookHandler(log, webhooks))))),
+			),
+		),
+	)
+	mux.Handle("/webhooks",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewListWebhooksHandler(log, webhooks))))),
+			),
+		),
+	)
+	mux.Handle("/webhook_dead_letters",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewListDeadLettersHandler(log, webhooks))))),
+			),
+		),
+	)
+	mux.Handle("/register",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewRegisterHandler(log, users))))),
+			),
+		),
+	)
+	mux.Handle("/login",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				limit(http.HandlerFunc(handlers.NewLoginHandler(log, users))),
 			),
 		),
 	)
+	mux.Handle("/logout",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewLogoutHandler(log, users)))),
+			),
+		),
+	)
+	mux.Handle("/admin/create_api_key",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewAddAPIKeyHandler(log, apiKeys))))),
+			),
+		),
+	)
+	mux.Handle("/admin/api_keys",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewListAPIKeysHandler(log, apiKeys))))),
+			),
+		),
+	)
+	mux.Handle("/admin/rotate_api_key",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewRotateAPIKeyHandler(log, apiKeys))))),
+			),
+		),
+	)
+	mux.Handle("/admin/revoke_api_key",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewRevokeAPIKeyHandler(log, apiKeys))))),
+			),
+		),
+	)
+	mux.Handle("/admin/export_tenant",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewExportTenantHandler(log, tenants))))),
+			),
+		),
+	)
+	mux.Handle("/admin/delete_tenant",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(isAdmin(http.HandlerFunc(handlers.NewDeleteTenantHandler(log, tenants))))),
+			),
+		),
+	)
+	mux.Handle("/events/stream",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewEventsStreamHandler(log, service, feed, cfg.Heartbeat)))),
+			),
+		),
+	)
+	mux.Handle("/events/ws",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(handlers.NewEventsWSHandler(log, service, feed)))),
+			),
+		),
+	)
+	mux.Handle("/graphql",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(
+				authn(limit(http.HandlerFunc(gql.NewHandler(log, service, profiles)))),
+			),
+		),
+	)
+
+	mux.Handle("/healthz",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(handlers.NewHealthzHandler()),
+		),
+	)
+	mux.Handle("/readyz",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(handlers.NewReadyzHandler(log, readiness)),
+		),
+	)
+	mux.Handle("/metrics",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})),
+		),
+	)
+	mux.Handle("/openapi.json",
+		middleware.NewMWLogger(log)(
+			middleware.RequestID(spec.Handler()),
+		),
+	)
 
+	if err := spec.Verify(mux.Patterns()); err != nil {
+		log.Error("openapi spec does not match routes", sl.Err(err))
+		os.Exit(1)
+	}
+
+	if cfg.GRPC.Address != "" {
+		components.Add(grpcComponent(log, cfg.GRPC.Address, service, verifiers, anonymous,
+			grpcserver.RateLimitPolicy{Limiter: preAuthLimiter, KeyBy: ratelimit.KeyByIP, Limit: preAuthLimit(cfg)},
+			grpcserver.RateLimitPolicy{Limiter: rateLimiter, KeyBy: policy.KeyBy, Limit: policy.Default},
+		))
+	}
 
 	srv := &http.Server{
 		Addr:         cfg.Address,
-		Handler:      mux,
+		Handler:      middleware.Metrics(metrics.NewHTTP(registry))(mux),
 		ReadTimeout:  cfg.HTTPServer.Timeout,
 		WriteTimeout: cfg.HTTPServer.Timeout,
 		IdleTimeout:  cfg.IdleTimeout,
 	}
 
-	log.Info("starting HTTP server",
-		slog.String("address", cfg.Address),
-		slog.Duration("read_timeout", cfg.HTTPServer.Timeout),
-		slog.Duration("write_timeout", cfg.HTTPServer.Timeout),
-		slog.Duration("idle_timeout", cfg.IdleTimeout),
-	)
+	// потоковые соединения не считаются активными запросами, Shutdown
+	// их не дождется: закрытие журнала завершает SSE и WebSocket
+	srv.RegisterOnShutdown(feed.Close)
+	components.Add(lifecycle.Component{
+		Name: "http server",
+		Start: func() error {
+			log.Info("starting HTTP server",
+				slog.String("address", cfg.Address),
+				slog.Duration("read_timeout", cfg.HTTPServer.Timeout),
+				slog.Duration("write_timeout", cfg.HTTPServer.Timeout),
+				slog.Duration("idle_timeout", cfg.IdleTimeout),
+			)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: srv.Shutdown,
+	})
+	// останавливается первым: готовность проваливается, пока сервер
+	// еще принимает запросы
+	components.Add(lifecycle.Component{
+		Name: "readiness",
+		Stop: func(ctx context.Context) error {
+			readiness.Shutdown()
+			select {
+			case <-time.After(cfg.Health.DrainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Error("failed to start server", sl.Err(err))
+	if err := components.Run(ctx, cfg.ShutdownTimeout); err != nil {
+		log.Error("server stopped with error", sl.Err(err))
+		os.Exit(1)
 	}
+	log.Info("server stopped")
 }
 
 func setupLogger(env string) *slog.Logger {
@@ -113,3 +493,177 @@ func setupLogger(env string) *slog.Logger {
 
 	return log
 }
+
+// readinessChecks проверки /readyz: хранилище событий отвечает, фоновые
+// компоненты работают
+func readinessChecks(cfg *config.Config, storage event.Storage, components *lifecycle.Manager) *health.Checker {
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Add(health.Check{
+		Name: "event storage",
+		Run: func(ctx context.Context) error {
+			_, err := storage.Count(ctx)
+			return err
+		},
+	})
+	workers := []string{"reminder scheduler", "webhook dispatcher"}
+	if cfg.GRPC.Address != "" {
+		workers = append(workers, "grpc server")
+	}
+	for _, name := range workers {
+		checker.Add(health.Check{
+			Name: name,
+			Run:  func(context.Context) error { return components.Running(name) },
+		})
+	}
+	return checker
+}
+
+// grpcComponent gRPC API на отдельном порту с тем же event.Service, что и
+// у HTTP обработчиков. Остановка ждет текущие вызовы, по истечении срока
+// обрывает их
+func grpcComponent(log *slog.Logger, addr string, service event.Service, verifiers map[string]middleware.TokenVerifier, anonymous bool, preAuth, limit grpcserver.RateLimitPolicy) lifecycle.Component {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Error("failed to listen gRPC address", sl.Err(err))
+		os.Exit(1)
+	}
+
+	grpcVerifiers := make(map[string]grpcserver.TokenVerifier, len(verifiers))
+	for scheme, v := range verifiers {
+		grpcVerifiers[scheme] = v
+	}
+	srv := grpcserver.New(log, service, grpcVerifiers, anonymous, preAuth, limit)
+
+	return lifecycle.Component{
+		Name: "grpc server",
+		Start: func() error {
+			log.Info("starting gRPC server", slog.String("address", addr))
+			return srv.Serve(lis)
+		},
+		Stop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+func mustReminderLedger(log *slog.Logger, cfg *config.Config) reminder.Ledger {
+	if cfg.Reminders.LedgerPath == "" {
+		log.Warn("reminder ledger path is not set, sent reminders are kept in memory")
+		return inmem.NewReminderLedger()
+	}
+	ledger, err := file.NewReminderLedger(cfg.Reminders.LedgerPath)
+	if err != nil {
+		log.Error("failed to open reminder ledger", sl.Err(err))
+		os.Exit(1)
+	}
+	return ledger
+}
+
+// tenantQuotas переводит квоты организаций из конфига в tenant.Quotas
+func tenantQuotas(cfg *config.Config) tenant.Quotas {
+	q := tenant.Quotas{
+		Default: tenant.Quota{MaxEvents: cfg.Tenants.MaxEvents},
+		Orgs:    make(map[uint64]tenant.Quota, len(cfg.Tenants.Orgs)),
+	}
+	for org, o := range cfg.Tenants.Orgs {
+		q.Orgs[org] = tenant.Quota{MaxEvents: o.MaxEvents}
+	}
+	return q
+}
+
+// mustRateLimitPolicy переводит лимиты из конфига в политику middleware
+func mustRateLimitPolicy(log *slog.Logger, cfg *config.Config) middleware.RateLimitPolicy {
+	rl := cfg.RateLimit
+	switch rl.KeyBy {
+	case ratelimit.KeyByAPIKey, ratelimit.KeyByUser, ratelimit.KeyByIP:
+	default:
+		log.Error("unknown rate_limit.key_by", slog.String("key_by", rl.KeyBy))
+		os.Exit(1)
+	}
+
+	policy := middleware.RateLimitPolicy{
+		KeyBy:   rl.KeyBy,
+		Default: ratelimit.Limit{Requests: rl.Requests, Per: rl.Per, Burst: rl.Burst},
+		Routes:  make(map[string]ratelimit.Limit, len(rl.Routes)),
+	}
+	for path, r := range rl.Routes {
+		if r.Per == 0 {
+			r.Per = rl.Per
+		}
+		policy.Routes[path] = ratelimit.Limit{Requests: r.Requests, Per: r.Per, Burst: r.Burst}
+	}
+	return policy
+}
+
+// preAuthLimit лимит запросов с одного адреса до аутентификации, пустой
+// Per берется из общего
+func preAuthLimit(cfg *config.Config) ratelimit.Limit {
+	r := cfg.RateLimit.PreAuth
+	if r.Per == 0 {
+		r.Per = cfg.RateLimit.Per
+	}
+	return ratelimit.Limit{Requests: r.Requests, Per: r.Per, Burst: r.Burst}
+}
+
+// sessionIssuer подписывает токены /login тем же HMAC секретом, которым они
+// проверяются. Без секрета вход по паролю выключен
+func sessionIssuer(log *slog.Logger, cfg *config.Config) user.TokenIssuer {
+	if cfg.Auth.HMACSecret == "" {
+		log.Warn("auth.hmac_secret is not set, password login is disabled")
+		return nil
+	}
+	return jwtauth.NewSigner([]byte(cfg.Auth.HMACSecret), cfg.Auth.Issuer, cfg.Auth.Audience)
+}
+
+// mustVerifiers собирает проверку учетных данных: API ключи всегда и JWT,
+// если в конфиге есть ключи. Без ключей JWT анонимные запросы пропускаются,
+// а запросы с учетными данными проверяются, см. anonymous; в prod это
+// ошибка конфигурации
+func mustVerifiers(log *slog.Logger, cfg *config.Config, apiKeys apikey.Service, users user.Service) (verifiers map[string]middleware.TokenVerifier, anonymous bool) {
+	verifiers = map[string]middleware.TokenVerifier{
+		middleware.SchemeAPIKey: apiKeys,
+	}
+	if cfg.Auth.HMACSecret == "" && cfg.Auth.JWKSPath == "" {
+		if cfg.Env == envProd {
+			log.Error("authentication is not configured, set auth.hmac_secret or auth.jwks_path")
+			os.Exit(1)
+		}
+		log.Warn("jwt authentication is disabled, requests without credentials are anonymous")
+		return verifiers, true
+	}
+
+	opts := jwtauth.Options{
+		HMACSecret: []byte(cfg.Auth.HMACSecret),
+		Issuer:     cfg.Auth.Issuer,
+		Audience:   cfg.Auth.Audience,
+		Leeway:     cfg.Auth.Leeway,
+		Sessions:   users,
+	}
+	if cfg.Auth.JWKSPath != "" {
+		keys, err := jwtauth.LoadJWKS(cfg.Auth.JWKSPath)
+		if err != nil {
+			log.Error("failed to load jwks", sl.Err(err))
+			os.Exit(1)
+		}
+		opts.RSAKeys = keys
+	}
+
+	verifier, err := jwtauth.NewVerifier(opts)
+	if err != nil {
+		log.Error("failed to create token verifier", sl.Err(err))
+		os.Exit(1)
+	}
+	verifiers[middleware.SchemeBearer] = verifier
+	return verifiers, false
+}
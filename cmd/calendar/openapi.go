@@ -0,0 +1,525 @@
+package main
+
+import (
+	gql "calendar/internal/infrastructure/graphql"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/openapi"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/ical"
+	"net/http"
+	"slices"
+)
+
+const (
+	tagEvents      = "events"
+	tagScheduling  = "scheduling"
+	tagPreferences = "preferences"
+	tagSharing     = "sharing"
+	tagWebhooks    = "webhooks"
+	tagRealtime    = "realtime"
+	tagUsers       = "users"
+	tagAdmin       = "admin"
+	tagMeta        = "meta"
+)
+
+var apiInfo = openapi.Info{
+	Title:   "Calendar API",
+	Version: "1.0.0",
+}
+
+const (
+	schemeBearer = "bearerAuth"
+	schemeAPIKey = "apiKeyAuth"
+)
+
+var apiSecuritySchemes = openapi.SecuritySchemes{
+	schemeBearer: {
+		Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+		Description: "HS256 or RS256 token, sub is the user UUID. " +
+			"GET endpoints also accept the access_token query parameter",
+	},
+	schemeAPIKey: {
+		Type: "apiKey", In: "header", Name: "Authorization",
+		Description: "ApiKey <key> issued by /admin/create_api_key. " +
+			"read_only keys get 403 on endpoints that change data",
+	},
+}
+
+// apiOperations описание маршрутов из main. Типы тел совпадают с теми, что
+// декодируют и возвращают обработчики, это проверяет TestSpecMatchesHandlers;
+// при добавлении маршрута без описания сервер не запустится, см. Spec.Verify
+func apiOperations() []openapi.Operation {
+	dateParam := openapi.Param{
+		Name: "date", Type: "string", Format: "date", Required: true,
+		Description: "YYYY-MM-DD",
+	}
+	annotateParam := openapi.Param{
+		Name: "annotate", Type: "string",
+		Description: "working_hours adds outsideWorkingHours to every event",
+	}
+
+	listEvents := func(path, summary string) openapi.Operation {
+		return openapi.Operation{
+			Method: http.MethodGet, Path: path, Summary: summary, Tag: tagEvents,
+			Query: []openapi.Param{dateParam, annotateParam},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.GetEventResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusInternalServerError),
+			},
+		}
+	}
+
+	return limited(secured([]openapi.Operation{
+		{
+			Method: http.MethodPost, Path: "/create_event", Summary: "Create an event", Tag: tagEvents,
+			Description: "Fails with 403 quota_exceeded when the organization reached its event quota",
+			Request:     dto.AddEventRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.AddEventResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/update_event", Summary: "Replace an event", Tag: tagEvents,
+			Request: dto.UpdateEventRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.UpdateEventResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusNotFound),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/delete_event", Summary: "Delete an event", Tag: tagEvents,
+			Request: dto.DeleteEventRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.DeleteEventResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusNotFound),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		listEvents("/events_for_day", "Events of the day"),
+		listEvents("/events_for_week", "Events of the week containing the date"),
+		listEvents("/events_for_month", "Events of the month containing the date"),
+		{
+			Method: http.MethodPost, Path: "/freebusy", Summary: "Busy intervals of users", Tag: tagScheduling,
+			Description: "Returns VFREEBUSY when format=ics or Accept: text/calendar",
+			Query: []openapi.Param{
+				{Name: "format", Type: "string", Description: "ics for iCalendar output"},
+			},
+			Request: dto.FreeBusyRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.FreeBusyResponse{}},
+				{Status: http.StatusOK, ContentType: ical.ContentType},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/suggest_slots", Summary: "Common free slots for a meeting", Tag: tagScheduling,
+			Request: dto.SuggestSlotsRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.SuggestSlotsResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/create_preferences", Summary: "Create an availability profile", Tag: tagPreferences,
+			Request: dto.PreferencesRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.PreferencesResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusConflict),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/update_preferences", Summary: "Replace an availability profile", Tag: tagPreferences,
+			Request: dto.PreferencesRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.PreferencesResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusNotFound),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/delete_preferences", Summary: "Delete an availability profile", Tag: tagPreferences,
+			Request: dto.DeletePreferencesRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.DeletePreferencesResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusNotFound),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/preferences", Summary: "Availability profile of a user", Tag: tagPreferences,
+			Query: []openapi.Param{{Name: "user", Type: "integer", Format: "int64", Description: "Defaults to the token user, other users get 403"}},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.PreferencesResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusNotFound),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/share_calendar", Summary: "Grant a role in a calendar", Tag: tagSharing,
+			Description: "Roles: freebusy, viewer, editor, owner. everyone shares with the whole company " +
+				"and accepts only freebusy or viewer. Only owners can change access",
+			Request: dto.ShareCalendarRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ShareCalendarResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusForbidden),
+				problem(http.StatusConflict),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/unshare_calendar", Summary: "Revoke a role in a calendar", Tag: tagSharing,
+			Request: dto.UnshareCalendarRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.UnshareCalendarResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusForbidden),
+				problem(http.StatusNotFound),
+				problem(http.StatusConflict),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/calendar_acl", Summary: "Access list of a calendar", Tag: tagSharing,
+			Query: []openapi.Param{{Name: "calendar", Type: "integer", Format: "int64", Required: true}},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.CalendarACLResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/create_share_link", Summary: "Publish a read-only link to a calendar", Tag: tagSharing,
+			Description: "The token is returned only once. Only owners can manage links",
+			Request:     dto.CreateShareLinkRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ShareLinkResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/share_links", Summary: "Share links of a calendar without tokens", Tag: tagSharing,
+			Query: []openapi.Param{{Name: "calendar", Type: "integer", Format: "int64", Required: true}},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ListShareLinksResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/revoke_share_link", Summary: "Revoke a share link", Tag: tagSharing,
+			Request: dto.RevokeShareLinkRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.RevokeShareLinkResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusNotFound),
+				problem(http.StatusGone),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/shared", Summary: "Public feed of a share link", Tag: tagSharing,
+			Description: "No authentication, the token grants read-only access. " +
+				"Returns VEVENT when format=ics or Accept: text/calendar",
+			Query: []openapi.Param{
+				{Name: "token", Type: "string", Required: true},
+				{Name: "from", Type: "string", Format: "date", Description: "YYYY-MM-DD"},
+				{Name: "to", Type: "string", Format: "date", Description: "YYYY-MM-DD, inclusive"},
+				{Name: "format", Type: "string", Description: "ics for iCalendar output"},
+			},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.GetEventResponse{}},
+				{Status: http.StatusOK, ContentType: ical.ContentType},
+				problem(http.StatusBadRequest),
+				problem(http.StatusNotFound),
+				problem(http.StatusGone),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/create_webhook", Summary: "Subscribe a URL to event changes", Tag: tagWebhooks,
+			Description: "Admin only. Deliveries include only events the creating admin can view",
+			Request:     dto.AddWebhookRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.AddWebhookResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/delete_webhook", Summary: "Delete a webhook subscription", Tag: tagWebhooks,
+			Description: "Admin only",
+			Request:     dto.DeleteWebhookRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.DeleteWebhookResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusNotFound),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/webhooks", Summary: "List webhook subscriptions", Tag: tagWebhooks,
+			Description: "Admin only",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ListWebhooksResponse{}},
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/register", Summary: "Create a user account", Tag: tagUsers,
+			Description: "Admin only, the user is created in the admin's organization. " +
+				"Open to anonymous requests only when JWT authentication is not configured",
+			Request: dto.RegisterRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.RegisterResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusConflict),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/login", Summary: "Open a session with email and password", Tag: tagUsers,
+			Description: "Returns an HS256 bearer token bound to the session",
+			Request:     dto.LoginRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.LoginResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusUnauthorized),
+				problem(http.StatusForbidden),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/logout", Summary: "Revoke the session of the request token", Tag: tagUsers,
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.LogoutResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/admin/create_api_key", Summary: "Issue an API key for a user", Tag: tagAdmin,
+			Description: "The key is returned only in this response",
+			Request:     dto.CreateAPIKeyRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.APIKeyResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/admin/api_keys", Summary: "List API keys without secrets", Tag: tagAdmin,
+			Query: []openapi.Param{
+				{Name: "user", Type: "integer", Format: "int64", Description: "Only keys of this user"},
+			},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ListAPIKeysResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/admin/rotate_api_key", Summary: "Replace an API key with a new secret", Tag: tagAdmin,
+			Description: "The old key stops working immediately",
+			Request:     dto.RotateAPIKeyRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.APIKeyResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusNotFound),
+				problem(http.StatusConflict),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/admin/revoke_api_key", Summary: "Revoke an API key", Tag: tagAdmin,
+			Request: dto.RevokeAPIKeyRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.RevokeAPIKeyResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusNotFound),
+				problem(http.StatusConflict),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/admin/export_tenant", Summary: "Export all data of the caller organization", Tag: tagAdmin,
+			Description: "Webhook secrets and share link tokens are not exported",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ExportTenantResponse{}},
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/admin/delete_tenant", Summary: "Delete all data of the caller organization", Tag: tagAdmin,
+			Description: "orgUUID must match the organization of the caller",
+			Request:     dto.DeleteTenantRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.DeleteTenantResponse{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusForbidden),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/webhook_dead_letters", Summary: "Deliveries that exhausted retries", Tag: tagWebhooks,
+			Description: "Admin only",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ListDeadLettersResponse{}},
+				problem(http.StatusForbidden),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/events/stream", Summary: "Server-sent stream of event changes", Tag: tagRealtime,
+			Description: "Resumes after Last-Event-ID header or lastEventId parameter",
+			Query: []openapi.Param{
+				{Name: "user", Type: "integer", Format: "int64"},
+				{Name: "calendar", Type: "integer", Format: "int64"},
+				{Name: "lastEventId", Type: "integer", Format: "int64"},
+			},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, ContentType: "text/event-stream"},
+				problem(http.StatusBadRequest),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/events/ws", Summary: "WebSocket range subscriptions and event commands", Tag: tagRealtime,
+			Description: "JSON messages: subscribe, unsubscribe, create, update, delete",
+			Responses: []openapi.Response{
+				{Status: http.StatusSwitchingProtocols},
+				problem(http.StatusBadRequest),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/graphql", Summary: "GraphQL queries and mutations", Tag: tagEvents,
+			Request: gql.GraphQLRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: map[string]any{}},
+				problem(http.StatusBadRequest),
+				problem(http.StatusRequestEntityTooLarge),
+				problem(http.StatusUnsupportedMediaType),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/healthz", Summary: "Liveness probe", Tag: tagMeta,
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.HealthResponse{}},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", Summary: "Readiness probe with per-check report", Tag: tagMeta,
+			Description: "503 when a check fails or the server is shutting down",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.HealthResponse{}},
+				{Status: http.StatusServiceUnavailable, Body: dto.HealthResponse{}},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/metrics", Summary: "Prometheus metrics", Tag: tagMeta,
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, ContentType: "text/plain"},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/openapi.json", Summary: "This document", Tag: tagMeta,
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: map[string]any{}},
+			},
+		},
+	}, "/openapi.json", "/healthz", "/readyz", "/metrics", "/login", "/shared"),
+		"/openapi.json", "/healthz", "/readyz", "/metrics")
+}
+
+// secured требует токен у всех операций, кроме путей public, и добавляет
+// им ответ 401
+func secured(ops []openapi.Operation, public ...string) []openapi.Operation {
+	for i := range ops {
+		if slices.Contains(public, ops[i].Path) {
+			continue
+		}
+		ops[i].Security = []string{schemeBearer, schemeAPIKey}
+		ops[i].Responses = append(ops[i].Responses, problem(http.StatusUnauthorized))
+	}
+	return ops
+}
+
+// limited добавляет ответ 429 всем операциям, кроме путей unlimited
+func limited(ops []openapi.Operation, unlimited ...string) []openapi.Operation {
+	for i := range ops {
+		if !slices.Contains(unlimited, ops[i].Path) {
+			ops[i].Responses = append(ops[i].Responses, problem(http.StatusTooManyRequests))
+		}
+	}
+	return ops
+}
+
+// problem ответ с ошибкой в формате response.Problem
+func problem(status int) openapi.Response {
+	return openapi.Response{Status: status, ContentType: response.ContentTypeProblem, Body: response.Problem{}}
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bytes"
+	"calendar/internal/acl"
+	"calendar/internal/apikey"
+	"calendar/internal/auth"
+	"calendar/internal/changefeed"
+	"calendar/internal/event"
+	"calendar/internal/health"
+	jwtauth "calendar/internal/infrastructure/auth/jwt"
+	gql "calendar/internal/infrastructure/graphql"
+	"calendar/internal/infrastructure/http/handlers"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/openapi"
+	"calendar/internal/infrastructure/storage/in_memory"
+	"calendar/internal/preferences"
+	"calendar/internal/scheduling"
+	"calendar/internal/sharelink"
+	"calendar/internal/tenant"
+	"calendar/internal/tenantadmin"
+	"calendar/internal/user"
+	"calendar/internal/webhook"
+	"calendar/pkg/sl_logger/slog_discard"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// specStep запрос к обработчику маршрута. body должен иметь тип Request
+// операции, ответ сверяется с Body ее успешного ответа
+type specStep struct {
+	path    string
+	handler http.Handler
+	query   string
+	body    any
+	// identity пользователь запроса, по умолчанию администратор
+	identity *auth.Identity
+	// noCall только проверка метода: поток и WebSocket не отвечают DTO
+	noCall bool
+	// anyMethod обработчик принимает любой метод
+	anyMethod bool
+}
+
+// TestSpecMatchesHandlers проверяет для каждой операции спецификации, что
+// обработчик ее маршрута принимает только ее метод, декодирует тело ее
+// типа Request (неизвестные поля отклоняются) и отвечает телом ее типа Body
+func TestSpecMatchesHandlers(t *testing.T) {
+	ops := apiOperations()
+	openapi.MustNew(apiInfo, apiSecuritySchemes, ops)
+
+	steps := specSteps(t)
+
+	byPath := make(map[string]openapi.Operation, len(ops))
+	for _, o := range ops {
+		byPath[o.Path] = o
+	}
+	seen := make(map[string]bool, len(steps))
+	for _, s := range steps {
+		if seen[s.path] {
+			t.Fatalf("%s: duplicate step", s.path)
+		}
+		seen[s.path] = true
+		if _, ok := byPath[s.path]; !ok {
+			t.Errorf("%s: step without operation in the spec", s.path)
+		}
+	}
+	for _, o := range ops {
+		if !seen[o.Path] {
+			t.Errorf("%s %s: operation without a step", o.Method, o.Path)
+		}
+	}
+
+	for _, s := range steps {
+		o, ok := byPath[s.path]
+		if !ok {
+			continue
+		}
+		t.Run(s.path, func(t *testing.T) {
+			checkMethod(t, o, s)
+			if s.noCall {
+				return
+			}
+			checkRequest(t, o, s)
+		})
+	}
+}
+
+func specSteps(t *testing.T) []specStep {
+	t.Helper()
+
+	log := slogdiscard.NewDiscardLogger()
+	calendars := acl.NewService(inmem.NewACL())
+	service := event.NewService(inmem.New(), calendars, tenant.Quotas{})
+	profiles := preferences.NewService(inmem.NewPreferences())
+	planner := scheduling.NewService(service, profiles)
+	shareLinks := sharelink.NewService(inmem.NewShareLinks(), service, calendars)
+	webhooks := webhook.NewService(inmem.NewWebhooks(), webhook.Guard{AllowPrivate: true})
+	apiKeys := apikey.NewService(inmem.NewAPIKeys())
+	userStorage := inmem.NewUsers()
+	users := user.NewService(userStorage, userStorage,
+		jwtauth.NewSigner([]byte("test-secret"), "", ""), time.Hour)
+	tenants := tenantadmin.NewService(tenantadmin.Services{
+		Events:     service,
+		ACL:        calendars,
+		Webhooks:   webhooks,
+		ShareLinks: shareLinks,
+		Profiles:   profiles,
+		Users:      users,
+		APIKeys:    apiKeys,
+	})
+	feed := changefeed.NewLog(16)
+	service.Subscribe(feed)
+	registry := prometheus.NewRegistry()
+	spec := openapi.MustNew(apiInfo, apiSecuritySchemes, apiOperations())
+
+	admin := adminIdentity()
+	ctx := withIdentity(context.Background(), admin)
+
+	const calendarUUID = 7
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 2)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+	// данные, на которые ссылаются запросы удаления, отзыва и ротации
+	eventUUID, err := service.Add(ctx, event.Event{
+		UserUUID: admin.UserUUID, CalendarUUID: calendarUUID,
+		Date: at(9), End: at(10), Title: "standup", Desc: "daily",
+	})
+	if err != nil {
+		t.Fatalf("add event: %v", err)
+	}
+	link, token, err := shareLinks.Create(ctx, sharelink.Link{CalendarUUID: calendarUUID})
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+	sub, err := webhooks.Subscribe(ctx, webhook.Subscription{URL: "https://hooks.example.com/seed"})
+	if err != nil {
+		t.Fatalf("subscribe webhook: %v", err)
+	}
+	key, _, err := apiKeys.Create(ctx, admin.UserUUID, "seed", apikey.ScopeReadOnly)
+	if err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+	if _, err := users.Register(ctx, "bob@example.com", "Bob", "password-bob"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	session, _, err := users.Login("bob@example.com", "password-bob")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	loggedIn := auth.Identity{
+		UserUUID: session.UserUUID, OrgUUID: session.OrgUUID, Method: "jwt",
+		Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}, SessionID: session.ID,
+	}
+
+	hours := dto.WorkingHours{Start: "09:00", End: "18:00", Weekdays: []int{0, 1, 2, 3, 4, 5, 6}, TimeZone: "UTC"}
+	prefs := dto.PreferencesRequest{
+		UserUUID:     admin.UserUUID,
+		TimeZone:     "Europe/Moscow",
+		WorkingHours: []dto.DayHours{{Weekday: 1, Start: "09:00", End: "18:00"}},
+		OutOfOffice:  []dto.OutOfOffice{{Start: at(24), End: at(48), Reason: "vacation"}},
+	}
+	from, to, expires := at(0), at(24), at(72)
+	date := "date=" + day.Format(time.DateOnly)
+	calendar := "calendar=" + strconv.Itoa(calendarUUID)
+	org := tenant.Default
+
+	return []specStep{
+		{path: "/register", handler: handlers.NewRegisterHandler(log, users),
+			body: dto.RegisterRequest{Email: "alice@example.com", Name: "Alice", Password: "password-alice"}},
+		{path: "/login", handler: handlers.NewLoginHandler(log, users),
+			body: dto.LoginRequest{Email: "alice@example.com", Password: "password-alice"}},
+		{path: "/logout", handler: handlers.NewLogoutHandler(log, users), identity: &loggedIn},
+		{path: "/create_event", handler: handlers.NewAddEventHandler(log, service),
+			body: dto.AddEventRequest{
+				UserUUID: admin.UserUUID, CalendarUUID: calendarUUID, Date: at(11), End: at(12),
+				Title: "review", Desc: "design review", Reminders: []dto.Reminder{{MinutesBefore: 15}},
+			}},
+		{path: "/update_event", handler: handlers.NewUpdateEventHandler(log, service),
+			body: dto.UpdateEventRequest{
+				UUID: eventUUID, UserUUID: admin.UserUUID, CalendarUUID: calendarUUID, Date: at(9), End: at(10),
+				Title: "standup", Desc: "daily sync", Reminders: []dto.Reminder{{MinutesBefore: 5}},
+			}},
+		{path: "/events_for_day", handler: handlers.NewEventsForDayHandler(log, service, profiles),
+			query: date + "&annotate=working_hours"},
+		{path: "/events_for_week", handler: handlers.NewEventsForWeekHandler(log, service, profiles), query: date},
+		{path: "/events_for_month", handler: handlers.NewEventsForMonthHandler(log, service, profiles), query: date},
+		{path: "/freebusy", handler: handlers.NewFreeBusyHandler(log, service),
+			body: dto.FreeBusyRequest{Users: []uint64{admin.UserUUID}, From: from, To: to}},
+		{path: "/suggest_slots", handler: handlers.NewSuggestSlotsHandler(log, planner),
+			body: dto.SuggestSlotsRequest{
+				Participants: []uint64{admin.UserUUID}, DurationMinutes: 30, From: from, To: to,
+				WorkingHours: &hours, ParticipantHours: map[uint64]dto.WorkingHours{admin.UserUUID: hours},
+				BufferMinutes: 5, StepMinutes: 15, Limit: 3, Rank: "earliest",
+			}},
+		{path: "/create_preferences", handler: handlers.NewAddPreferencesHandler(log, profiles), body: prefs},
+		{path: "/update_preferences", handler: handlers.NewUpdatePreferencesHandler(log, profiles), body: prefs},
+		{path: "/preferences", handler: handlers.NewGetPreferencesHandler(log, profiles),
+			query: "user=" + strconv.FormatUint(admin.UserUUID, 10)},
+		{path: "/share_calendar", handler: handlers.NewShareCalendarHandler(log, calendars),
+			body: dto.ShareCalendarRequest{CalendarUUID: calendarUUID, UserUUID: 2, Role: "viewer"}},
+		{path: "/calendar_acl", handler: handlers.NewCalendarACLHandler(log, calendars), query: calendar},
+		{path: "/unshare_calendar", handler: handlers.NewUnshareCalendarHandler(log, calendars),
+			body: dto.UnshareCalendarRequest{CalendarUUID: calendarUUID, UserUUID: 2}},
+		{path: "/create_share_link", handler: handlers.NewCreateShareLinkHandler(log, shareLinks),
+			body: dto.CreateShareLinkRequest{CalendarUUID: calendarUUID, From: &from, To: &to, ExpiresAt: &expires}},
+		{path: "/share_links", handler: handlers.NewListShareLinksHandler(log, shareLinks), query: calendar},
+		{path: "/shared", handler: handlers.NewSharedEventsHandler(log, shareLinks), query: "token=" + token},
+		{path: "/revoke_share_link", handler: handlers.NewRevokeShareLinkHandler(log, shareLinks),
+			body: dto.RevokeShareLinkRequest{ID: link.ID}},
+		{path: "/create_webhook", handler: handlers.NewAddWebhookHandler(log, webhooks),
+			body: dto.AddWebhookRequest{
+				URL: "https://hooks.example.com/calendar", Secret: "0123456789abcdef", Types: []string{"created"},
+			}},
+		{path: "/webhooks", handler: handlers.NewListWebhooksHandler(log, webhooks)},
+		{path: "/webhook_dead_letters", handler: handlers.NewListDeadLettersHandler(log, webhooks)},
+		{path: "/delete_webhook", handler: handlers.NewDeleteWebhookHandler(log, webhooks),
+			body: dto.DeleteWebhookRequest{ID: sub.ID}},
+		{path: "/admin/create_api_key", handler: handlers.NewAddAPIKeyHandler(log, apiKeys),
+			body: dto.CreateAPIKeyRequest{UserUUID: admin.UserUUID, Name: "ci", Scope: string(apikey.ScopeReadWrite)}},
+		{path: "/admin/api_keys", handler: handlers.NewListAPIKeysHandler(log, apiKeys),
+			query: "user=" + strconv.FormatUint(admin.UserUUID, 10)},
+		{path: "/admin/rotate_api_key", handler: handlers.NewRotateAPIKeyHandler(log, apiKeys),
+			body: dto.RotateAPIKeyRequest{ID: key.ID}},
+		{path: "/admin/revoke_api_key", handler: handlers.NewRevokeAPIKeyHandler(log, apiKeys),
+			body: dto.RevokeAPIKeyRequest{ID: key.ID}},
+		{path: "/graphql", handler: gql.NewHandler(log, service, profiles),
+			body: gql.GraphQLRequest{Query: "{ __typename }"}},
+		{path: "/delete_event", handler: handlers.NewDeleteEventHandler(log, service),
+			body: dto.DeleteEventRequest{UUID: eventUUID}},
+		{path: "/delete_preferences", handler: handlers.NewDeletePreferencesHandler(log, profiles),
+			body: dto.DeletePreferencesRequest{UserUUID: admin.UserUUID}},
+		{path: "/events/stream", handler: handlers.NewEventsStreamHandler(log, service, feed, time.Second), noCall: true},
+		{path: "/events/ws", handler: handlers.NewEventsWSHandler(log, service, feed), noCall: true},
+		{path: "/healthz", handler: handlers.NewHealthzHandler()},
+		{path: "/readyz", handler: handlers.NewReadyzHandler(log, health.NewChecker(time.Second))},
+		{path: "/metrics", handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}), anyMethod: true},
+		{path: "/openapi.json", handler: spec.Handler()},
+		{path: "/admin/export_tenant", handler: handlers.NewExportTenantHandler(log, tenants)},
+		{path: "/admin/delete_tenant", handler: handlers.NewDeleteTenantHandler(log, tenants),
+			body: dto.DeleteTenantRequest{OrgUUID: &org}},
+	}
+}
+
+func adminIdentity() auth.Identity {
+	return auth.Identity{
+		UserUUID: 1,
+		OrgUUID:  tenant.Default,
+		Subject:  "1",
+		Method:   "jwt",
+		Scopes:   []auth.Scope{auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin},
+	}
+}
+
+// withIdentity кладет в контекст то же, что middleware.Authenticate
+func withIdentity(ctx context.Context, id auth.Identity) context.Context {
+	return tenant.WithOrg(auth.WithIdentity(ctx, id), id.OrgUUID)
+}
+
+func serve(s specStep, method string, body []byte) *httptest.ResponseRecorder {
+	target := s.path
+	if s.query != "" {
+		target += "?" + s.query
+	}
+	r := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	id := adminIdentity()
+	if s.identity != nil {
+		id = *s.identity
+	}
+	r = r.WithContext(withIdentity(r.Context(), id))
+
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, r)
+	return w
+}
+
+func checkMethod(t *testing.T, o openapi.Operation, s specStep) {
+	t.Helper()
+	if s.anyMethod {
+		return
+	}
+
+	other := http.MethodGet
+	if o.Method == http.MethodGet {
+		other = http.MethodPost
+	}
+	w := serve(s, other, nil)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("%s: got %d, want %d", other, w.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := w.Header().Get("Allow"); allow != o.Method {
+		t.Errorf("Allow %q, want %q", allow, o.Method)
+	}
+}
+
+func checkRequest(t *testing.T, o openapi.Operation, s specStep) {
+	t.Helper()
+
+	var body []byte
+	if got, want := reflect.TypeOf(s.body), reflect.TypeOf(o.Request); got != want {
+		t.Fatalf("step body %v, spec request %v", got, want)
+	}
+	if s.body != nil {
+		var err error
+		if body, err = json.Marshal(s.body); err != nil {
+			t.Fatalf("marshal request: %v", err)
+		}
+	}
+
+	w := serve(s, o.Method, body)
+	res, ok := successResponse(o, w.Code)
+	if !ok {
+		t.Fatalf("%s: status %d is not a documented success: %s", o.Method, w.Code, w.Body)
+	}
+
+	if res.Body == nil {
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, res.ContentType) {
+			t.Errorf("Content-Type %q, want %q", ct, res.ContentType)
+		}
+		return
+	}
+	var got any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v: %s", err, w.Body)
+	}
+	checkJSON(t, "response", reflect.TypeOf(res.Body), got)
+}
+
+// successResponse описание 2xx ответа со статусом status, JSON описание
+// предпочтительнее: обработчики отдают другие форматы только по запросу
+func successResponse(o openapi.Operation, status int) (openapi.Response, bool) {
+	if status < 200 || status > 299 {
+		return openapi.Response{}, false
+	}
+	var found []openapi.Response
+	for _, res := range o.Responses {
+		if res.Status == status {
+			found = append(found, res)
+		}
+	}
+	for _, res := range found {
+		if res.Body != nil {
+			return res, true
+		}
+	}
+	if len(found) > 0 {
+		return found[0], true
+	}
+	return openapi.Response{}, false
+}
+
+var jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+
+// checkJSON сверяет значение из ответа с типом: у объектов нет полей,
+// которых нет в типе, и есть все поля без omitempty
+func checkJSON(t *testing.T, path string, typ reflect.Type, v any) {
+	t.Helper()
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if v == nil {
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Interface:
+			return
+		}
+		if reflect.PointerTo(typ).Implements(jsonMarshaler) {
+			return
+		}
+	}
+	if typ.Implements(jsonMarshaler) || reflect.PointerTo(typ).Implements(jsonMarshaler) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			t.Errorf("%s: got %T, want object %v", path, v, typ)
+			return
+		}
+		fields := jsonFields(typ)
+		for name, value := range obj {
+			f, ok := fields[name]
+			if !ok {
+				t.Errorf("%s.%s: field is not in %v", path, name, typ)
+				continue
+			}
+			checkJSON(t, path+"."+name, f.typ, value)
+		}
+		for name, f := range fields {
+			if _, ok := obj[name]; !ok && !f.omitEmpty {
+				t.Errorf("%s.%s: field of %v is missing", path, name, typ)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := v.([]any)
+		if !ok {
+			t.Errorf("%s: got %T, want array of %v", path, v, typ.Elem())
+			return
+		}
+		for i, e := range arr {
+			checkJSON(t, path+"["+strconv.Itoa(i)+"]", typ.Elem(), e)
+		}
+	case reflect.Map:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			t.Errorf("%s: got %T, want object %v", path, v, typ)
+			return
+		}
+		for k, e := range obj {
+			checkJSON(t, path+"."+k, typ.Elem(), e)
+		}
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			t.Errorf("%s: got %T, want string", path, v)
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			t.Errorf("%s: got %T, want bool", path, v)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := v.(float64); !ok {
+			t.Errorf("%s: got %T, want number", path, v)
+		}
+	}
+}
+
+type jsonField struct {
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+// jsonFields поля структуры так, как их видит encoding/json, со
+// встроенными структурами без тега
+func jsonFields(typ reflect.Type) map[string]jsonField {
+	fields := make(map[string]jsonField)
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for n, inner := range jsonFields(f.Type) {
+				fields[n] = inner
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = jsonField{typ: f.Type, omitEmpty: strings.Contains(opts, "omitempty")}
+	}
+	return fields
+}
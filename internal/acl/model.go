@@ -0,0 +1,51 @@
+package acl
+
+// Role уровень доступа к календарю, роли упорядочены: каждая следующая
+// включает права предыдущей
+type Role int
+
+const (
+	RoleNone Role = iota
+	// RoleFreeBusy только занятость без содержимого событий
+	RoleFreeBusy
+	RoleViewer
+	RoleEditor
+	RoleOwner
+)
+
+var roleNames = map[Role]string{
+	RoleNone:     "none",
+	RoleFreeBusy: "freebusy",
+	RoleViewer:   "viewer",
+	RoleEditor:   "editor",
+	RoleOwner:    "owner",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseRole обратная к String, RoleNone не разбирается: отзыв доступа
+// выполняется через Revoke
+func ParseRole(s string) (Role, bool) {
+	for r, name := range roleNames {
+		if name == s && r != RoleNone {
+			return r, true
+		}
+	}
+	return RoleNone, false
+}
+
+// Everyone принципал «вся компания», то есть любой аутентифицированный
+// пользователь. UserUUID пользователей начинаются с 1
+const Everyone uint64 = 0
+
+// Grant роль пользователя или Everyone в календаре
+type Grant struct {
+	CalendarUUID uint64
+	UserUUID     uint64
+	Role         Role
+}
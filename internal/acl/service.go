@@ -0,0 +1,158 @@
+// Package acl provides совместный доступ к календарям: роли пользователей
+// и общий доступ к занятости для всей компании
+package acl
+
+import (
+	"calendar/internal/auth"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrForbidden    = errors.New("only calendar owner can change access")
+	ErrNotFound     = errors.New("grant not found")
+	ErrLastOwner    = errors.New("calendar must keep at least one owner")
+	ErrInvalidGrant = errors.New("everyone can be granted only freebusy or viewer")
+	ErrNoCalendar   = errors.New("calendar uuid is required")
+)
+
+type Service interface {
+	// Grant выдает роль, менять доступ может только владелец календаря
+	Grant(ctx context.Context, g Grant) error
+	Revoke(ctx context.Context, calendarUUID, userUUID uint64) error
+	List(ctx context.Context, calendarUUID uint64) ([]Grant, error)
+
+	// RoleOf роль пользователя с учетом доступа для Everyone
+	RoleOf(ctx context.Context, calendarUUID, userUUID uint64) (Role, error)
+	// Claimed сообщает, есть ли у календаря записи доступа
+	Claimed(ctx context.Context, calendarUUID uint64) (bool, error)
+	// Claim делает пользователя владельцем календаря без записей доступа
+	// и ничего не меняет, если у календаря они уже есть. Вызывается после
+	// первой успешной записи в календарь
+	Claim(ctx context.Context, calendarUUID, userUUID uint64) error
+
+	// Export все записи доступа организации без проверки ролей
+	Export(ctx context.Context) ([]Grant, error)
+	Purge(ctx context.Context) (int, error)
+}
+
+type service struct {
+	storage Storage
+}
+
+func NewService(storage Storage) Service {
+	return &service{storage: storage}
+}
+
+func (s *service) Grant(ctx context.Context, g Grant) error {
+	const op = "acl.grant"
+
+	if g.CalendarUUID == 0 {
+		return fmt.Errorf("%s: %w", op, ErrNoCalendar)
+	}
+	if g.UserUUID == Everyone && g.Role > RoleViewer {
+		return fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+	}
+	// календарь без записей доступа получает владельца только с первым
+	// событием, выдать к нему доступ до этого нельзя
+	if err := s.requireOwner(ctx, g.CalendarUUID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if g.Role != RoleOwner {
+		if err := s.keepOwner(ctx, g.CalendarUUID, g.UserUUID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return s.storage.Put(ctx, g)
+}
+
+func (s *service) Revoke(ctx context.Context, calendarUUID, userUUID uint64) error {
+	const op = "acl.revoke"
+
+	if err := s.requireOwner(ctx, calendarUUID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.keepOwner(ctx, calendarUUID, userUUID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return s.storage.Delete(ctx, calendarUUID, userUUID)
+}
+
+func (s *service) List(ctx context.Context, calendarUUID uint64) ([]Grant, error) {
+	const op = "acl.list"
+
+	if err := s.requireOwner(ctx, calendarUUID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.storage.List(ctx, calendarUUID)
+}
+
+func (s *service) RoleOf(ctx context.Context, calendarUUID, userUUID uint64) (Role, error) {
+	grants, err := s.storage.List(ctx, calendarUUID)
+	if err != nil {
+		return RoleNone, err
+	}
+	role := RoleNone
+	for _, g := range grants {
+		if (g.UserUUID == userUUID || g.UserUUID == Everyone) && g.Role > role {
+			role = g.Role
+		}
+	}
+	return role, nil
+}
+
+func (s *service) Claimed(ctx context.Context, calendarUUID uint64) (bool, error) {
+	grants, err := s.storage.List(ctx, calendarUUID)
+	if err != nil {
+		return false, err
+	}
+	return len(grants) > 0, nil
+}
+
+func (s *service) Claim(ctx context.Context, calendarUUID, userUUID uint64) error {
+	return s.storage.Claim(ctx, Grant{CalendarUUID: calendarUUID, UserUUID: userUUID, Role: RoleOwner})
+}
+
+func (s *service) Export(ctx context.Context) ([]Grant, error) {
+	return s.storage.ListAll(ctx)
+}
+
+func (s *service) Purge(ctx context.Context) (int, error) {
+	return s.storage.Purge(ctx)
+}
+
+// requireOwner без аутентификации пропускает всех, как auth.Require
+func (s *service) requireOwner(ctx context.Context, calendarUUID uint64) error {
+	id, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	role, err := s.RoleOf(ctx, calendarUUID, id.UserUUID)
+	if err != nil {
+		return err
+	}
+	if role < RoleOwner {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// keepOwner не дает понизить или удалить последнего владельца
+func (s *service) keepOwner(ctx context.Context, calendarUUID, userUUID uint64) error {
+	grants, err := s.storage.List(ctx, calendarUUID)
+	if err != nil {
+		return err
+	}
+	owners, target := 0, false
+	for _, g := range grants {
+		if g.Role == RoleOwner {
+			owners++
+			target = target || g.UserUUID == userUUID
+		}
+	}
+	if target && owners == 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package acl
+
+import "context"
+
+// Storage записи доступа организации из ctx, номера календарей разных
+// организаций не пересекаются
+type Storage interface {
+	// Put создает или заменяет роль принципала в календаре
+	Put(ctx context.Context, g Grant) error
+	Delete(ctx context.Context, calendarUUID, userUUID uint64) error
+	List(ctx context.Context, calendarUUID uint64) ([]Grant, error)
+	// Claim атомарно кладет g, только если у календаря еще нет записей
+	Claim(ctx context.Context, g Grant) error
+	// ListAll записи всех календарей организации
+	ListAll(ctx context.Context) ([]Grant, error)
+	// Purge удаляет все записи организации и возвращает их число
+	Purge(ctx context.Context) (int, error)
+}
@@ -0,0 +1,47 @@
+package apikey
+
+import (
+	"calendar/internal/auth"
+	"time"
+)
+
+// Scope набор прав ключа
+type Scope string
+
+const (
+	ScopeReadOnly  Scope = "read_only"
+	ScopeReadWrite Scope = "read_write"
+)
+
+// Scopes права пользователя, которые дает ключ. Ключи не дают
+// auth.ScopeAdmin: управлять ключами можно только интерактивно
+func (s Scope) Scopes() []auth.Scope {
+	switch s {
+	case ScopeReadOnly:
+		return []auth.Scope{auth.ScopeRead}
+	case ScopeReadWrite:
+		return []auth.Scope{auth.ScopeRead, auth.ScopeWrite}
+	}
+	return nil
+}
+
+// Key API ключ пользователя. Сам ключ имеет вид <Prefix>.<secret>, хранится
+// только SHA-256 секретной части, по Prefix ключ ищется в хранилище
+type Key struct {
+	ID       uint64
+	UserUUID uint64
+	// OrgUUID организация, в которой действует ключ
+	OrgUUID    uint64
+	Name       string
+	Scope      Scope
+	Prefix     string
+	Hash       []byte
+	CreatedAt  time.Time
+	RotatedAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+}
+
+func (k Key) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
@@ -0,0 +1,205 @@
+// Package apikey provides API ключи для доступа ботов и фоновых задач без
+// интерактивного входа
+package apikey
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/tenant"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("api key not found")
+	ErrRevoked      = errors.New("api key is revoked")
+	ErrInvalidScope = errors.New("unknown api key scope")
+	ErrMalformedKey = errors.New("malformed api key")
+)
+
+const (
+	// prefixTag помогает узнать ключ в логах и сканерах секретов
+	prefixTag   = "ck_"
+	prefixBytes = 6
+	secretBytes = 32
+)
+
+// Service ключи организации из ctx, ключи других организаций не видны
+type Service interface {
+	// Create выпускает ключ и возвращает его вместе с открытым текстом,
+	// который больше нигде не сохраняется
+	Create(ctx context.Context, userUUID uint64, name string, scope Scope) (Key, string, error)
+	List(ctx context.Context, userUUID uint64) ([]Key, error)
+	// Rotate заменяет ключ новым с теми же правами, старый сразу перестает
+	// действовать
+	Rotate(ctx context.Context, id uint64) (Key, string, error)
+	Revoke(ctx context.Context, id uint64) error
+	// Verify проверяет ключ из заголовка Authorization: ApiKey <key>
+	Verify(key string) (auth.Identity, error)
+	// Purge удаляет все ключи организации из ctx
+	Purge(ctx context.Context) (int, error)
+}
+
+type service struct {
+	storage Storage
+	now     func() time.Time
+}
+
+func NewService(storage Storage) Service {
+	return &service{storage: storage, now: time.Now}
+}
+
+func (s *service) Create(ctx context.Context, userUUID uint64, name string, scope Scope) (Key, string, error) {
+	const op = "apikey.create"
+
+	if scope.Scopes() == nil {
+		return Key{}, "", fmt.Errorf("%s: %w: %q", op, ErrInvalidScope, scope)
+	}
+
+	k := Key{
+		UserUUID:  userUUID,
+		OrgUUID:   tenant.FromContext(ctx),
+		Name:      name,
+		Scope:     scope,
+		CreatedAt: s.now(),
+	}
+	plain, err := k.issue()
+	if err != nil {
+		return Key{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.storage.Add(k)
+	if err != nil {
+		return Key{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	k.ID = id
+	return k, plain, nil
+}
+
+func (s *service) List(ctx context.Context, userUUID uint64) ([]Key, error) {
+	keys, err := s.storage.List(userUUID)
+	if err != nil {
+		return nil, err
+	}
+	org := tenant.FromContext(ctx)
+	res := keys[:0]
+	for _, k := range keys {
+		if k.OrgUUID == org {
+			res = append(res, k)
+		}
+	}
+	return res, nil
+}
+
+// get ключ организации из ctx, чужой ключ выглядит как отсутствующий
+func (s *service) get(ctx context.Context, id uint64) (Key, error) {
+	const op = "apikey.get"
+
+	k, err := s.storage.Get(id)
+	if err != nil {
+		return Key{}, err
+	}
+	if k.OrgUUID != tenant.FromContext(ctx) {
+		return Key{}, fmt.Errorf("%s: error: %w, %v", op, ErrNotFound, id)
+	}
+	return k, nil
+}
+
+func (s *service) Rotate(ctx context.Context, id uint64) (Key, string, error) {
+	const op = "apikey.rotate"
+
+	k, err := s.get(ctx, id)
+	if err != nil {
+		return Key{}, "", err
+	}
+	if k.Revoked() {
+		return Key{}, "", fmt.Errorf("%s: %w: %d", op, ErrRevoked, id)
+	}
+
+	plain, err := k.issue()
+	if err != nil {
+		return Key{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	k.RotatedAt = s.now()
+	if err := s.storage.Update(k); err != nil {
+		return Key{}, "", err
+	}
+	return k, plain, nil
+}
+
+func (s *service) Revoke(ctx context.Context, id uint64) error {
+	const op = "apikey.revoke"
+
+	k, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if k.Revoked() {
+		return fmt.Errorf("%s: %w: %d", op, ErrRevoked, id)
+	}
+	k.RevokedAt = s.now()
+	return s.storage.Update(k)
+}
+
+func (s *service) Verify(key string) (auth.Identity, error) {
+	const op = "apikey.verify"
+
+	prefix, secret, ok := strings.Cut(key, ".")
+	if !ok || !strings.HasPrefix(prefix, prefixTag) || secret == "" {
+		return auth.Identity{}, fmt.Errorf("%s: %w: %v", op, auth.ErrUnauthenticated, ErrMalformedKey)
+	}
+
+	k, err := s.storage.GetByPrefix(prefix)
+	if err != nil {
+		return auth.Identity{}, fmt.Errorf("%s: %w: %v", op, auth.ErrUnauthenticated, err)
+	}
+	sum := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare(sum[:], k.Hash) != 1 {
+		return auth.Identity{}, fmt.Errorf("%s: %w: secret mismatch for %s", op, auth.ErrUnauthenticated, prefix)
+	}
+	if k.Revoked() {
+		return auth.Identity{}, fmt.Errorf("%s: %w: %v", op, auth.ErrUnauthenticated, ErrRevoked)
+	}
+
+	// ошибка учета использования не повод отказывать в доступе
+	_ = s.storage.Touch(k.ID, s.now())
+
+	return auth.Identity{
+		UserUUID: k.UserUUID,
+		OrgUUID:  k.OrgUUID,
+		Subject:  "api_key:" + strconv.FormatUint(k.ID, 10),
+		Method:   "api_key",
+		Scopes:   k.Scope.Scopes(),
+	}, nil
+}
+
+func (s *service) Purge(ctx context.Context) (int, error) {
+	return s.storage.Purge(tenant.FromContext(ctx))
+}
+
+// issue генерирует новые префикс и секрет ключа и возвращает открытый текст.
+// Секрет случайный и длинный, поэтому для хранения хватает SHA-256 без соли
+func (k *Key) issue() (string, error) {
+	p := make([]byte, prefixBytes)
+	if _, err := rand.Read(p); err != nil {
+		return "", err
+	}
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	k.Prefix = prefixTag + hex.EncodeToString(p)
+	plainSecret := base64.RawURLEncoding.EncodeToString(secret)
+	sum := sha256.Sum256([]byte(plainSecret))
+	k.Hash = sum[:]
+	return k.Prefix + "." + plainSecret, nil
+}
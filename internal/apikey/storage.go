@@ -0,0 +1,16 @@
+package apikey
+
+import "time"
+
+type Storage interface {
+	Add(k Key) (uint64, error)
+	Get(id uint64) (Key, error)
+	GetByPrefix(prefix string) (Key, error)
+	Update(k Key) error
+	// List ключи пользователя, при userUUID == 0 все ключи
+	List(userUUID uint64) ([]Key, error)
+	// Touch обновляет время последнего использования
+	Touch(id uint64, at time.Time) error
+	// Purge удаляет ключи организации и возвращает их число
+	Purge(orgUUID uint64) (int, error)
+}
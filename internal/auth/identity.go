@@ -0,0 +1,70 @@
+// Package auth описывает пользователя, от имени которого выполняется
+// запрос, независимо от способа аутентификации и транспорта
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrUnauthenticated учетные данные не переданы или не прошли проверку
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrInsufficientScope у пользователя нет нужного права
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Scope право на класс операций
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	// ScopeAdmin управление API ключами и другими учетными данными
+	ScopeAdmin Scope = "admin"
+)
+
+// Identity пользователь запроса
+type Identity struct {
+	UserUUID uint64
+	// OrgUUID организация пользователя, см. пакет tenant
+	OrgUUID uint64
+	// Subject исходный идентификатор из учетных данных, для логов
+	Subject string
+	// Method способ аутентификации, например jwt или api_key
+	Method string
+	Scopes []Scope
+	// SessionID сеанс входа по паролю, пустой для внешних токенов и ключей
+	SessionID string
+}
+
+func (id Identity) Has(s Scope) bool {
+	return slices.Contains(id.Scopes, s)
+}
+
+type ctxKey struct{}
+
+// WithIdentity сохраняет пользователя запроса в контексте
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext возвращает пользователя запроса, если запрос аутентифицирован
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(ctxKey{}).(Identity)
+	return id, ok
+}
+
+// Require проверяет право пользователя из контекста. Контекст без
+// пользователя бывает только при выключенной аутентификации, такие
+// запросы пропускаются: транспорты отклоняют неаутентифицированные
+// запросы раньше
+func Require(ctx context.Context, s Scope) error {
+	id, ok := FromContext(ctx)
+	if !ok || id.Has(s) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInsufficientScope, s)
+}
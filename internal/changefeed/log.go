@@ -0,0 +1,146 @@
+// Package changefeed provides ограниченный журнал изменений событий в памяти
+// с подпиской и возобновлением с последнего полученного идентификатора
+package changefeed
+
+import (
+	"calendar/internal/event"
+	"errors"
+	"sync"
+)
+
+const (
+	DefaultSize   = 1000
+	subscriberBuf = 64
+)
+
+// Причины закрытия канала подписки, см. Subscription.Err
+var (
+	ErrLagged = errors.New("subscriber lagged behind")
+	ErrClosed = errors.New("change log is closed")
+)
+
+// Entry изменение с монотонно растущим идентификатором
+type Entry struct {
+	ID     uint64       `json:"id"`
+	Change event.Change `json:"change"`
+}
+
+// Log кольцевой буфер последних изменений. Реализует event.Observer
+type Log struct {
+	mu     sync.Mutex
+	buf    []Entry
+	start  int
+	lastID uint64
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func NewLog(size int) *Log {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Log{
+		buf:  make([]Entry, 0, size),
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+func (l *Log) OnChange(c event.Change) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastID++
+	e := Entry{ID: l.lastID, Change: c}
+	if len(l.buf) < cap(l.buf) {
+		l.buf = append(l.buf, e)
+	} else {
+		l.buf[l.start] = e
+		l.start = (l.start + 1) % len(l.buf)
+	}
+
+	for s := range l.subs {
+		select {
+		case s.ch <- e:
+		default:
+			// медленный подписчик отключается и переподключается через Last-Event-ID
+			l.drop(s, ErrLagged)
+		}
+	}
+}
+
+// Subscribe возвращает изменения после afterID из журнала и подписку на новые.
+// complete=false если часть изменений после afterID уже вытеснена из буфера
+func (l *Log) Subscribe(afterID uint64) (backlog []Entry, sub *Subscription, complete bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	complete = true
+	if afterID > 0 && len(l.buf) > 0 {
+		oldest := l.buf[l.start].ID
+		if afterID+1 < oldest {
+			complete = false
+		}
+		for i := range l.buf {
+			e := l.buf[(l.start+i)%len(l.buf)]
+			if e.ID > afterID {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+	if afterID > l.lastID {
+		complete = false
+	}
+
+	sub = &Subscription{log: l, ch: make(chan Entry, subscriberBuf)}
+	l.subs[sub] = struct{}{}
+	if l.closed {
+		l.drop(sub, ErrClosed)
+	}
+	return backlog, sub, complete
+}
+
+// Close закрывает все подписки с ErrClosed, новые подписки сразу закрыты.
+// Вызывается при остановке сервера, чтобы потоковые соединения завершились
+func (l *Log) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for s := range l.subs {
+		l.drop(s, ErrClosed)
+	}
+}
+
+// drop вызывается под l.mu
+func (l *Log) drop(s *Subscription, reason error) {
+	if _, ok := l.subs[s]; !ok {
+		return
+	}
+	delete(l.subs, s)
+	s.err = reason
+	close(s.ch)
+}
+
+type Subscription struct {
+	log *Log
+	ch  chan Entry
+	// err причина закрытия ch, пишется под log.mu до закрытия канала
+	err error
+}
+
+// C канал новых изменений, закрывается при отписке, отставании подписчика
+// или закрытии журнала
+func (s *Subscription) C() <-chan Entry {
+	return s.ch
+}
+
+// Err причина закрытия канала C, читать после того, как канал закрыт.
+// nil при закрытии через Close подписки
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.log.mu.Lock()
+	defer s.log.mu.Unlock()
+	s.log.drop(s, nil)
+}
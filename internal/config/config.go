@@ -11,6 +11,16 @@ import (
 type Config struct{
 	Env string `yaml:"env" env-defaut:"dev"`
 	HTTPServer `yaml:"http_server"`
+	GRPC       GRPCServer `yaml:"grpc_server"`
+	Reminders  `yaml:"reminders"`
+	Webhooks   `yaml:"webhooks"`
+	Stream     `yaml:"stream"`
+	Auth       `yaml:"auth"`
+	Tenants    `yaml:"tenants"`
+	RateLimit  `yaml:"rate_limit"`
+	Health     `yaml:"health"`
+	// ShutdownTimeout срок на остановку серверов и воркеров после SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"15s"`
 }
 
 type HTTPServer struct{
@@ -19,6 +29,85 @@ type HTTPServer struct{
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
 }
 
+// GRPCServer настройки gRPC API. Пустой Address отключает сервер
+type GRPCServer struct {
+	Address string `yaml:"address"`
+}
+
+// Reminders настройки планировщика напоминаний. Пустой LedgerPath означает
+// журнал отправленных в памяти без защиты от повторов после перезапуска
+type Reminders struct {
+	LedgerPath string `yaml:"ledger_path"`
+}
+
+type Webhooks struct {
+	Workers         int           `yaml:"workers" env-default:"4"`
+	MaxAttempts     int           `yaml:"max_attempts" env-default:"5"`
+	BaseBackoff     time.Duration `yaml:"base_backoff" env-default:"1s"`
+	MaxBackoff      time.Duration `yaml:"max_backoff" env-default:"1m"`
+	DeliveryTimeout time.Duration `yaml:"delivery_timeout" env-default:"10s"`
+	// AllowPrivateHosts разрешает получателей в loopback и частных сетях,
+	// только для локальной разработки
+	AllowPrivateHosts bool `yaml:"allow_private_hosts" env-default:"false"`
+}
+
+type Stream struct {
+	ChangeLogSize int           `yaml:"change_log_size" env-default:"1000"`
+	Heartbeat     time.Duration `yaml:"heartbeat" env-default:"15s"`
+}
+
+// Auth настройки проверки JWT. Без HMACSecret и JWKSPath аутентификация
+// выключена, в prod сервер с такой конфигурацией не запустится
+type Auth struct {
+	HMACSecret string        `yaml:"hmac_secret" env:"AUTH_HMAC_SECRET"`
+	JWKSPath   string        `yaml:"jwks_path" env:"AUTH_JWKS_PATH"`
+	Issuer     string        `yaml:"issuer"`
+	Audience   string        `yaml:"audience"`
+	Leeway     time.Duration `yaml:"leeway" env-default:"30s"`
+	// SessionTTL срок токенов, выдаваемых /login. Вход по паролю
+	// работает только с HMACSecret
+	SessionTTL time.Duration `yaml:"session_ttl" env-default:"24h"`
+}
+
+// Tenants квоты организаций: MaxEvents по умолчанию и переопределения по
+// UUID организации в Orgs. 0 означает без ограничения
+type Tenants struct {
+	MaxEvents int                 `yaml:"max_events"`
+	Orgs      map[uint64]OrgQuota `yaml:"orgs"`
+}
+
+type OrgQuota struct {
+	MaxEvents int `yaml:"max_events"`
+}
+
+// RateLimit лимиты запросов HTTP API на клиента. KeyBy: api_key, user
+// или ip. Requests за Per с запасом Burst, 0 в Requests снимает лимит.
+// Routes переопределяют лимит для путей, пустой Per маршрута берется
+// из общего. PreAuth лимит запросов с одного адреса до проверки учетных
+// данных, на HTTP и gRPC. Корзины без запросов дольше IdleTTL удаляются
+type RateLimit struct {
+	KeyBy    string                `yaml:"key_by" env-default:"user"`
+	Requests int                   `yaml:"requests"`
+	Per      time.Duration         `yaml:"per" env-default:"1m"`
+	Burst    int                   `yaml:"burst"`
+	IdleTTL  time.Duration         `yaml:"idle_ttl" env-default:"10m"`
+	Routes   map[string]RouteLimit `yaml:"routes"`
+	PreAuth  RouteLimit            `yaml:"pre_auth"`
+}
+
+type RouteLimit struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
+// Health проверки /readyz. CheckTimeout срок одной проверки, DrainDelay
+// пауза между провалом готовности и остановкой HTTP сервера, чтобы
+// балансировщик успел перестать слать запросы
+type Health struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env-default:"2s"`
+	DrainDelay   time.Duration `yaml:"drain_delay" env-default:"0s"`
+}
 
 func MustLoad() *Config  {
 	configPath := os.Getenv("CONFIG_PATH")
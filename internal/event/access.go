@@ -0,0 +1,130 @@
+package event
+
+import (
+	"calendar/internal/acl"
+	"calendar/internal/auth"
+	"context"
+	"fmt"
+)
+
+// Access роли пользователей в календарях организации из ctx, реализуется
+// acl.Service
+type Access interface {
+	RoleOf(ctx context.Context, calendarUUID, userUUID uint64) (acl.Role, error)
+	Claimed(ctx context.Context, calendarUUID uint64) (bool, error)
+	Claim(ctx context.Context, calendarUUID, userUUID uint64) error
+}
+
+// caller роли пользователя запроса в пределах одного вызова сервиса:
+// роль каждого календаря запрашивается один раз
+type caller struct {
+	ctx    context.Context
+	access Access
+	user   uint64
+	known  map[uint64]acl.Role
+}
+
+// callerFrom возвращает nil, если проверять нечего: аутентификация
+// выключена или сервис создан без Access
+func (s *service) callerFrom(ctx context.Context) *caller {
+	id, ok := auth.FromContext(ctx)
+	if !ok || s.access == nil {
+		return nil
+	}
+	return &caller{ctx: ctx, access: s.access, user: id.UserUUID, known: make(map[uint64]acl.Role)}
+}
+
+// role события без календаря принадлежат автору, остальным видна только
+// их занятость. Для календарей роль берется из ACL
+func (c *caller) role(e Event) (acl.Role, error) {
+	if e.CalendarUUID == 0 {
+		if e.UserUUID == c.user {
+			return acl.RoleOwner, nil
+		}
+		return acl.RoleFreeBusy, nil
+	}
+	if r, ok := c.known[e.CalendarUUID]; ok {
+		return r, nil
+	}
+	r, err := c.access.RoleOf(c.ctx, e.CalendarUUID, c.user)
+	if err != nil {
+		return acl.RoleNone, err
+	}
+	c.known[e.CalendarUUID] = r
+	return r, nil
+}
+
+func (c *caller) require(op string, e Event, min acl.Role) error {
+	r, err := c.role(e)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if r < min {
+		return fmt.Errorf("%s: %w: calendar %d requires %s, have %s", op, ErrForbidden, e.CalendarUUID, min, r)
+	}
+	return nil
+}
+
+// requireVisible как require, но событие, которого пользователь не видит,
+// выглядит отсутствующим: иначе 403 выдавал бы существование чужих событий
+func (c *caller) requireVisible(op string, e Event, min acl.Role) error {
+	r, err := c.role(e)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if r < acl.RoleViewer {
+		return fmt.Errorf("%s: error: %w, %v", op, ErrNotFound, e.UUID)
+	}
+	return c.require(op, e, min)
+}
+
+// requireWrite в календарь без записей доступа может писать любой, владельцем
+// пользователь становится только после успешной записи, см. claim. true
+// означает, что календарь нужно занять
+func (c *caller) requireWrite(op string, e Event) (bool, error) {
+	if e.CalendarUUID != 0 {
+		r, err := c.role(e)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+		if r == acl.RoleNone {
+			claimed, err := c.access.Claimed(c.ctx, e.CalendarUUID)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", op, err)
+			}
+			if !claimed {
+				return true, nil
+			}
+		}
+	}
+	return false, c.require(op, e, acl.RoleEditor)
+}
+
+// claim делает пользователя владельцем календаря после записи в него. Если
+// календарь параллельно занял другой пользователь, возвращает ErrForbidden,
+// и запись нужно откатить
+func (c *caller) claim(op string, e Event) error {
+	if err := c.access.Claim(c.ctx, e.CalendarUUID, c.user); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	delete(c.known, e.CalendarUUID)
+	return c.require(op, e, acl.RoleEditor)
+}
+
+// filter оставляет события, к календарям которых есть роль не ниже min
+func (c *caller) filter(events []Event, min acl.Role) ([]Event, error) {
+	if c == nil {
+		return events, nil
+	}
+	res := events[:0:0]
+	for _, e := range events {
+		r, err := c.role(e)
+		if err != nil {
+			return nil, err
+		}
+		if r >= min {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
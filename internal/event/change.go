@@ -0,0 +1,25 @@
+package event
+
+import "time"
+
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// Change описывает успешное изменение события. Для удаления Event
+// содержит состояние события на момент удаления
+type Change struct {
+	Type  ChangeType `json:"type"`
+	Event Event      `json:"event"`
+	At    time.Time  `json:"at"`
+}
+
+// Observer получает изменения синхронно в вызове Add/Update/Delete,
+// поэтому реализация не должна блокироваться надолго
+type Observer interface {
+	OnChange(c Change)
+}
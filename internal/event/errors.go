@@ -0,0 +1,30 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Доменные ошибки событий. Реализации Storage оборачивают их через %w,
+// транспортные слои проверяют через errors.Is и не зависят от хранилища
+var (
+	ErrNotFound   = errors.New("event not found")
+	ErrConflict   = errors.New("event already exists")
+	ErrValidation = errors.New("invalid event")
+	ErrForbidden  = errors.New("access to event denied")
+)
+
+// validate проверяет инварианты события, которые не зависят от транспорта
+func validate(e Event) error {
+	const op = "event.validate"
+
+	if !e.End.IsZero() && !e.End.After(e.Date) {
+		return fmt.Errorf("%s: %w: end must be after date", op, ErrValidation)
+	}
+	for _, r := range e.Reminders {
+		if r.Before < 0 {
+			return fmt.Errorf("%s: %w: reminder must not be negative", op, ErrValidation)
+		}
+	}
+	return nil
+}
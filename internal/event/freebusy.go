@@ -0,0 +1,130 @@
+package event
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval полуинтервал [Start, End)
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreeBusy занятые интервалы одного пользователя без деталей событий
+type FreeBusy struct {
+	UserUUID uint64     `json:"userUUID"`
+	Busy     []Interval `json:"busy"`
+}
+
+// MergeIntervals сортирует интервалы и склеивает пересекающиеся и соседние
+func MergeIntervals(in []Interval) []Interval {
+	if len(in) == 0 {
+		return []Interval{}
+	}
+	sorted := make([]Interval, len(in))
+	copy(sorted, in)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	res := []Interval{sorted[0]}
+	for _, iv := range sorted[1:] {
+		last := &res[len(res)-1]
+		if !iv.Start.After(last.End) {
+			if iv.End.After(last.End) {
+				last.End = iv.End
+			}
+			continue
+		}
+		res = append(res, iv)
+	}
+	return res
+}
+
+// SubtractIntervals вычитает отсортированные непересекающиеся b из отсортированных a
+func SubtractIntervals(a, b []Interval) []Interval {
+	res := []Interval{}
+	j := 0
+	for _, w := range a {
+		cur := w.Start
+		for j < len(b) && !b[j].End.After(w.Start) {
+			j++
+		}
+		for k := j; k < len(b) && b[k].Start.Before(w.End); k++ {
+			if b[k].Start.After(cur) {
+				res = append(res, Interval{Start: cur, End: b[k].Start})
+			}
+			if b[k].End.After(cur) {
+				cur = b[k].End
+			}
+		}
+		if cur.Before(w.End) {
+			res = append(res, Interval{Start: cur, End: w.End})
+		}
+	}
+	return res
+}
+
+// IntersectIntervals пересечение двух отсортированных наборов непересекающихся интервалов
+func IntersectIntervals(a, b []Interval) []Interval {
+	res := []Interval{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if start.Before(end) {
+			res = append(res, Interval{Start: start, End: end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return res
+}
+
+// clip обрезает интервал по границам [from, to), ok=false если пересечения нет
+func (iv Interval) clip(from, to time.Time) (Interval, bool) {
+	if iv.Start.Before(from) {
+		iv.Start = from
+	}
+	if iv.End.After(to) {
+		iv.End = to
+	}
+	return iv, iv.Start.Before(iv.End)
+}
+
+func busyIntervals(events []Event, users []uint64, from, to time.Time) []FreeBusy {
+	byUser := make(map[uint64][]Interval, len(users))
+	for _, u := range users {
+		byUser[u] = nil
+	}
+	for _, e := range events {
+		if _, ok := byUser[e.UserUUID]; !ok {
+			continue
+		}
+		iv, ok := Interval{Start: e.Date, End: e.EndTime()}.clip(from, to)
+		if !ok {
+			continue
+		}
+		byUser[e.UserUUID] = append(byUser[e.UserUUID], iv)
+	}
+
+	res := make([]FreeBusy, 0, len(users))
+	seen := make(map[uint64]bool, len(users))
+	for _, u := range users {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		res = append(res, FreeBusy{UserUUID: u, Busy: MergeIntervals(byUser[u])})
+	}
+	return res
+}
@@ -2,10 +2,32 @@ package event
 
 import "time"
 
+// DefaultDuration используется для событий без явного времени окончания
+const DefaultDuration = time.Hour
+
+// Reminder напоминание за Before до начала события
+type Reminder struct {
+	Before time.Duration `json:"before"`
+}
+
 type Event struct {
-	UUID     uint64    `json:"UUID"`
-	UserUUID uint64    `json:"userUUID"`
-	Date     time.Time `json:"date"`
-	Title    string    `json:"title"`
-	Desc     string    `json:"description"`
+	UUID uint64 `json:"UUID"`
+	// OrgUUID организация события, задается хранилищем по контексту
+	OrgUUID      uint64     `json:"orgUUID"`
+	UserUUID     uint64     `json:"userUUID"`
+	CalendarUUID uint64     `json:"calendarUUID"`
+	Date         time.Time  `json:"date"`
+	End          time.Time  `json:"end"`
+	Title        string     `json:"title"`
+	Desc         string     `json:"description"`
+	Reminders    []Reminder `json:"reminders"`
+}
+
+// EndTime возвращает время окончания события, если End не задан
+// или раньше начала, событие считается длительностью DefaultDuration
+func (e Event) EndTime() time.Time {
+	if e.End.After(e.Date) {
+		return e.End
+	}
+	return e.Date.Add(DefaultDuration)
 }
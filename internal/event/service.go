@@ -1,45 +1,267 @@
 //Package event provides ...
 package event
 
-import "time"
+import (
+	"calendar/internal/acl"
+	"calendar/internal/tenant"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
 
+// Service работает с событиями организации из ctx (см. tenant.FromContext)
+// и проверяет доступ пользователя из ctx (см. auth.FromContext) к
+// календарю каждого события: чтение требует acl.RoleViewer, запись
+// acl.RoleEditor, FreeBusy учитывает календари с acl.RoleFreeBusy.
+// Списки молча пропускают недоступные события, а Get, Update и Delete
+// отвечают на них ErrNotFound. Календарь достается первому, кто успешно
+// записал в него событие
 type Service interface {
-	Add(e Event) error
-	Update(e Event) error
-	Delete(uuid uint64) error
-	ListByDay(t time.Time) ([]Event, error)
-	ListByWeek(t time.Time) ([]Event, error)
-	ListByMonth(t time.Time) ([]Event, error)
+	Add(ctx context.Context, e Event) (uint64, error)
+	Update(ctx context.Context, e Event) error
+	Delete(ctx context.Context, uuid uint64) error
+	Get(ctx context.Context, uuid uint64) (Event, error)
+	ListByDay(ctx context.Context, t time.Time) ([]Event, error)
+	ListByWeek(ctx context.Context, t time.Time) ([]Event, error)
+	ListByMonth(ctx context.Context, t time.Time) ([]Event, error)
+	ListByRange(ctx context.Context, from, to time.Time) ([]Event, error)
+	FreeBusy(ctx context.Context, users []uint64, from, to time.Time) ([]FreeBusy, error)
+	// ListAllByRange события всех организаций без проверки доступа, для
+	// фоновых задач процесса вроде загрузки напоминаний при старте
+	ListAllByRange(ctx context.Context, from, to time.Time) ([]Event, error)
+	// CanView сообщает, может ли пользователь из ctx видеть событие, для
+	// потоков изменений, которые идут мимо выборок сервиса
+	CanView(ctx context.Context, e Event) bool
+	// Export все события организации без проверки ролей, для администратора
+	Export(ctx context.Context) ([]Event, error)
+	// Purge удаляет все события организации, наблюдатели получают
+	// удаление каждого
+	Purge(ctx context.Context) (int, error)
+	// Subscribe регистрирует наблюдателя за успешными изменениями событий
+	Subscribe(o Observer)
 }
 
 type service struct {
 	storage Storage
+	access  Access
+	quotas  tenant.Quotas
+
+	mu        sync.RWMutex
+	observers []Observer
+}
+
+// NewService access может быть nil, тогда доступ не проверяется
+func NewService(storage Storage, access Access, quotas tenant.Quotas) Service {
+	return &service{storage: storage, access: access, quotas: quotas}
+}
+
+func (s *service) Add(ctx context.Context, e Event) (uint64, error) {
+	const op = "event.add"
+
+	if err := validate(e); err != nil {
+		return 0, err
+	}
+	c := s.callerFrom(ctx)
+	claim := false
+	if c != nil {
+		var err error
+		if claim, err = c.requireWrite(op, e); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.checkQuota(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	e.OrgUUID = tenant.FromContext(ctx)
+	id, err := s.storage.Add(ctx, e)
+	if err != nil {
+		return 0, err
+	}
+	e.UUID = id
+	if claim {
+		if err := c.claim(op, e); err != nil {
+			return 0, errors.Join(err, s.storage.Delete(ctx, id))
+		}
+	}
+	s.notify(ChangeCreated, e)
+	return id, nil
+}
+
+func (s *service) Update(ctx context.Context, e Event) error {
+	const op = "event.update"
+
+	if err := validate(e); err != nil {
+		return err
+	}
+	c := s.callerFrom(ctx)
+	claim := false
+	var old Event
+	if c != nil {
+		var err error
+		if old, err = s.storage.Get(ctx, e.UUID); err != nil {
+			return err
+		}
+		// редактор меняет событие, но не становится его владельцем
+		e.UserUUID = old.UserUUID
+		// и текущий календарь события, и новый, если событие переносится
+		if err := c.requireVisible(op, old, acl.RoleEditor); err != nil {
+			return err
+		}
+		if claim, err = c.requireWrite(op, e); err != nil {
+			return err
+		}
+	}
+	e.OrgUUID = tenant.FromContext(ctx)
+	if err := s.storage.Update(ctx, e); err != nil {
+		return err
+	}
+	if claim {
+		if err := c.claim(op, e); err != nil {
+			return errors.Join(err, s.storage.Update(ctx, old))
+		}
+	}
+	s.notify(ChangeUpdated, e)
+	return nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint64) error {
+	const op = "event.delete"
+
+	// запоминаем событие до удаления, чтобы наблюдатели получили его данные
+	e, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c := s.callerFrom(ctx); c != nil {
+		if err := c.requireVisible(op, e, acl.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := s.storage.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.notify(ChangeDeleted, e)
+	return nil
+}
+
+func (s *service) Get(ctx context.Context, id uint64) (Event, error) {
+	const op = "event.get"
+
+	e, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return Event{}, err
+	}
+	if c := s.callerFrom(ctx); c != nil {
+		if err := c.requireVisible(op, e, acl.RoleViewer); err != nil {
+			return Event{}, err
+		}
+	}
+	return e, nil
+}
+
+func (s *service) ListByDay(ctx context.Context, t time.Time) ([]Event, error) {
+	events, err := s.storage.ListByDay(ctx, t)
+	return s.visible(ctx, events, err)
+}
+
+func (s *service) ListByWeek(ctx context.Context, t time.Time) ([]Event, error) {
+	events, err := s.storage.ListByWeek(ctx, t)
+	return s.visible(ctx, events, err)
+}
+
+func (s *service) ListByMonth(ctx context.Context, t time.Time) ([]Event, error) {
+	events, err := s.storage.ListByMonth(ctx, t)
+	return s.visible(ctx, events, err)
 }
 
-func NewService(storage Storage) Service {
-	return &service{storage: storage}
+func (s *service) ListByRange(ctx context.Context, from, to time.Time) ([]Event, error) {
+	events, err := s.storage.ListByRange(ctx, from, to)
+	return s.visible(ctx, events, err)
 }
 
-func (s *service) Add(e Event) error {
-	return s.storage.Add(e)
+func (s *service) FreeBusy(ctx context.Context, users []uint64, from, to time.Time) ([]FreeBusy, error) {
+	events, err := s.storage.ListByRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	events, err = s.callerFrom(ctx).filter(events, acl.RoleFreeBusy)
+	if err != nil {
+		return nil, err
+	}
+	return busyIntervals(events, users, from, to), nil
 }
 
-func (s *service) Update(e Event) error {
-	return s.storage.Update(e)
+func (s *service) ListAllByRange(ctx context.Context, from, to time.Time) ([]Event, error) {
+	return s.storage.ListAllByRange(ctx, from, to)
+}
+
+func (s *service) CanView(ctx context.Context, e Event) bool {
+	if e.OrgUUID != tenant.FromContext(ctx) {
+		return false
+	}
+	c := s.callerFrom(ctx)
+	if c == nil {
+		return true
+	}
+	r, err := c.role(e)
+	return err == nil && r >= acl.RoleViewer
+}
+
+func (s *service) Export(ctx context.Context) ([]Event, error) {
+	return s.storage.List(ctx)
+}
+
+func (s *service) Purge(ctx context.Context) (int, error) {
+	const op = "event.purge"
+
+	deleted, err := s.storage.Purge(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, e := range deleted {
+		s.notify(ChangeDeleted, e)
+	}
+	return len(deleted), nil
 }
 
-func (s *service) Delete(id uint64) error {
-	return s.storage.Delete(id)
+// checkQuota число событий проверяется до записи, параллельные Add могут
+// превысить квоту на несколько событий
+func (s *service) checkQuota(ctx context.Context) error {
+	org := tenant.FromContext(ctx)
+	limit := s.quotas.For(org).MaxEvents
+	if limit <= 0 {
+		return nil
+	}
+	n, err := s.storage.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if n >= limit {
+		return fmt.Errorf("%w: organization %d has %d of %d events", tenant.ErrQuotaExceeded, org, n, limit)
+	}
+	return nil
 }
 
-func (s *service) ListByDay(t time.Time) ([]Event, error) {
-	return s.storage.ListByDay(t)
+func (s *service) visible(ctx context.Context, events []Event, err error) ([]Event, error) {
+	if err != nil {
+		return nil, err
+	}
+	return s.callerFrom(ctx).filter(events, acl.RoleViewer)
 }
 
-func (s *service) ListByWeek(t time.Time) ([]Event, error) {
-	return s.storage.ListByWeek(t)
+func (s *service) Subscribe(o Observer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, o)
 }
 
-func (s *service) ListByMonth(t time.Time) ([]Event, error) {
-	return s.storage.ListByMonth(t)
+func (s *service) notify(t ChangeType, e Event) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := Change{Type: t, Event: e, At: time.Now()}
+	for _, o := range s.observers {
+		o.OnChange(c)
+	}
 }
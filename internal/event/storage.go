@@ -1,12 +1,29 @@
 package event
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
+// Storage хранилище событий. Каждый метод работает только с событиями
+// организации из ctx (см. tenant.FromContext): чужие события не находятся и
+// не попадают в выборки. Ошибки реализаций оборачивают ErrNotFound,
+// ErrConflict и другие доменные ошибки пакета
 type Storage interface {
-	Add(e Event) error
-	Update(e Event) error
-	Delete(uuid uint64) error
-	ListByDay(t time.Time) ([]Event, error)
-	ListByWeek(t time.Time) ([]Event, error)
-	ListByMonth(t time.Time) ([]Event, error)
+	// Add записывает событие в организацию из ctx независимо от e.OrgUUID
+	Add(ctx context.Context, e Event) (uint64, error)
+	Update(ctx context.Context, e Event) error
+	Delete(ctx context.Context, uuid uint64) error
+	Get(ctx context.Context, uuid uint64) (Event, error)
+	ListByDay(ctx context.Context, t time.Time) ([]Event, error)
+	ListByWeek(ctx context.Context, t time.Time) ([]Event, error)
+	ListByMonth(ctx context.Context, t time.Time) ([]Event, error)
+	ListByRange(ctx context.Context, from, to time.Time) ([]Event, error)
+	// ListAllByRange как ListByRange, но по всем организациям сразу
+	ListAllByRange(ctx context.Context, from, to time.Time) ([]Event, error)
+	// List все события организации
+	List(ctx context.Context) ([]Event, error)
+	Count(ctx context.Context) (int, error)
+	// Purge удаляет все события организации и возвращает их
+	Purge(ctx context.Context) ([]Event, error)
 }
@@ -0,0 +1,116 @@
+// Package health provides проверки готовности процесса для оркестратора
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Check одна проверка готовности. Timeout 0 означает таймаут Checker
+type Check struct {
+	Name    string
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+type Result struct {
+	Name     string
+	Status   Status
+	Error    string
+	Duration time.Duration
+}
+
+// Report итог проверок, Status ok только если прошли все
+type Report struct {
+	Status Status
+	Checks []Result
+}
+
+// Checker выполняет проверки параллельно, каждую со своим таймаутом.
+// После Shutdown готовность всегда провалена
+type Checker struct {
+	timeout      time.Duration
+	checks       []Check
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add регистрирует проверку, вызывается до начала обслуживания запросов
+func (c *Checker) Add(check Check) {
+	c.checks = append(c.checks, check)
+}
+
+// Shutdown помечает процесс останавливающимся
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checker) Ready(ctx context.Context) Report {
+	if c.shuttingDown.Load() {
+		return Report{
+			Status: StatusFail,
+			Checks: []Result{{Name: "shutdown", Status: StatusFail, Error: ErrShuttingDown.Error()}},
+		}
+	}
+
+	res := Report{Status: StatusOK, Checks: make([]Result, len(c.checks))}
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.Checks[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range res.Checks {
+		if r.Status != StatusOK {
+			res.Status = StatusFail
+		}
+	}
+	return res
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	// проверка, не уважающая ctx, не должна задерживать ответ дольше таймаута
+	errc := make(chan error, 1)
+	go func() { errc <- check.Run(ctx) }()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	r := Result{Name: check.Name, Status: StatusOK, Duration: time.Since(started)}
+	if err != nil {
+		r.Status = StatusFail
+		r.Error = err.Error()
+	}
+	return r
+}
@@ -0,0 +1,37 @@
+package jwtauth
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signer выпускает HS256 токены сеансов тем же секретом, которым их
+// проверяет Verifier
+type Signer struct {
+	secret   []byte
+	issuer   string
+	audience string
+}
+
+func NewSigner(secret []byte, issuer, audience string) *Signer {
+	return &Signer{secret: secret, issuer: issuer, audience: audience}
+}
+
+func (s *Signer) Issue(userUUID, orgUUID uint64, sessionID string, expiresAt time.Time) (string, error) {
+	c := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(userUUID, 10),
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		OrgUUID:   orgUUID,
+		SessionID: sessionID,
+	}
+	if s.audience != "" {
+		c.Audience = jwt.ClaimStrings{s.audience}
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(s.secret)
+}
@@ -0,0 +1,148 @@
+// Package jwtauth проверяет JWT токены доступа: HS256 с общим секретом
+// и RS256 с ключами из локального JWKS файла
+package jwtauth
+
+import (
+	"calendar/internal/auth"
+
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoKeyMaterial  = errors.New("neither hmac secret nor jwks is configured")
+	ErrUnknownKey     = errors.New("unknown key id")
+	ErrInvalidSubject = errors.New("sub must be a numeric user uuid")
+)
+
+// Options источники ключей и ожидаемые claims. Пустые Issuer и Audience
+// не проверяются
+type Options struct {
+	HMACSecret []byte
+	RSAKeys    map[string]*rsa.PublicKey
+	Issuer     string
+	Audience   string
+	Leeway     time.Duration
+	// Sessions проверяет HS256 токены с sid, остальные не проверяются
+	Sessions SessionValidator
+}
+
+// roleAdmin значение claim roles, дающее auth.ScopeAdmin
+const roleAdmin = "admin"
+
+// claims стандартные поля, roles список ролей пользователя, org его
+// организация и sid сеанс, по которому токен выдан при входе по паролю
+type claims struct {
+	jwt.RegisteredClaims
+	Roles     []string `json:"roles,omitempty"`
+	OrgUUID   uint64   `json:"org,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+}
+
+// SessionValidator проверяет, что сеанс из claim sid не отозван
+type SessionValidator interface {
+	ValidateSession(id string) error
+}
+
+// Verifier проверяет подпись и срок действия токена и строит по нему
+// auth.Identity. В sub ожидается UserUUID пользователя, пользователь
+// получает чтение и запись, а с ролью admin еще и auth.ScopeAdmin
+type Verifier struct {
+	opts   Options
+	parser *jwt.Parser
+}
+
+func NewVerifier(opts Options) (*Verifier, error) {
+	const op = "jwtauth.new_verifier"
+
+	var methods []string
+	if len(opts.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(opts.RSAKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, ErrNoKeyMaterial)
+	}
+
+	parserOpts := []jwt.ParserOption{
+		// алгоритм берется из заголовка токена, поэтому список обязателен:
+		// иначе RS256 ключ можно выдать за HS256 секрет
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &Verifier{opts: opts, parser: jwt.NewParser(parserOpts...)}, nil
+}
+
+// Verify проверяет токен. Все ошибки оборачивают auth.ErrUnauthenticated
+func (v *Verifier) Verify(token string) (auth.Identity, error) {
+	const op = "jwtauth.verify"
+
+	var claims claims
+	parsed, err := v.parser.ParseWithClaims(token, &claims, v.key)
+	if err != nil {
+		return auth.Identity{}, fmt.Errorf("%s: %w: %v", op, auth.ErrUnauthenticated, err)
+	}
+
+	userUUID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userUUID == 0 {
+		return auth.Identity{}, fmt.Errorf("%s: %w: %v", op, auth.ErrUnauthenticated, ErrInvalidSubject)
+	}
+
+	// сеансы выпускает Signer и только HS256, sid в RS256 токенах внешнего
+	// провайдера к ним не относится
+	if claims.SessionID != "" && v.opts.Sessions != nil && parsed.Method == jwt.SigningMethodHS256 {
+		if err := v.opts.Sessions.ValidateSession(claims.SessionID); err != nil {
+			return auth.Identity{}, fmt.Errorf("%s: %w: %v", op, auth.ErrUnauthenticated, err)
+		}
+	}
+
+	scopes := []auth.Scope{auth.ScopeRead, auth.ScopeWrite}
+	if slices.Contains(claims.Roles, roleAdmin) {
+		scopes = append(scopes, auth.ScopeAdmin)
+	}
+
+	return auth.Identity{
+		UserUUID:  userUUID,
+		OrgUUID:   claims.OrgUUID,
+		Subject:   claims.Subject,
+		Method:    "jwt",
+		Scopes:    scopes,
+		SessionID: claims.SessionID,
+	}, nil
+}
+
+func (v *Verifier) key(t *jwt.Token) (any, error) {
+	switch t.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.opts.HMACSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := v.opts.RSAKeys[kid]; ok {
+			return key, nil
+		}
+		// токен без kid подходит, только если ключ один
+		if kid == "" && len(v.opts.RSAKeys) == 1 {
+			for _, key := range v.opts.RSAKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return nil, jwt.ErrTokenUnverifiable
+}
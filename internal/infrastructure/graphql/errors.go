@@ -0,0 +1,74 @@
+package gql
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	"calendar/internal/preferences"
+
+	"errors"
+)
+
+// Коды ошибок в extensions.code ответа GraphQL
+const (
+	codeBadUserInput = "BAD_USER_INPUT"
+	codeNotFound     = "NOT_FOUND"
+	codeConflict     = "CONFLICT"
+	codeForbidden    = "FORBIDDEN"
+	codeInternal     = "INTERNAL"
+)
+
+var (
+	errInvalidID    = errors.New("invalid id")
+	errInvalidRange = errors.New("range end must be after start")
+	errInvalidInput = errors.New("invalid input")
+	errInternal     = errors.New("internal error")
+)
+
+// resolverError ошибка резолвера с кодом и ошибками полей, graphql-go
+// переносит Extensions в ответ клиенту
+type resolverError struct {
+	code   string
+	err    error
+	fields map[string]string
+}
+
+func (e *resolverError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resolverError) Unwrap() error {
+	return e.err
+}
+
+func (e *resolverError) Extensions() map[string]any {
+	ext := map[string]any{"code": e.code}
+	if len(e.fields) > 0 {
+		ext["fields"] = e.fields
+	}
+	return ext
+}
+
+func badInput(err error) error {
+	return &resolverError{code: codeBadUserInput, err: err}
+}
+
+// serviceError переводит ошибки сервиса в ошибки резолвера, детали
+// внутренних ошибок клиенту не отдаются
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, event.ErrNotFound):
+		return &resolverError{code: codeNotFound, err: event.ErrNotFound}
+	case errors.Is(err, event.ErrConflict):
+		return &resolverError{code: codeConflict, err: event.ErrConflict}
+	case errors.Is(err, event.ErrValidation):
+		return &resolverError{code: codeBadUserInput, err: err}
+	case errors.Is(err, event.ErrForbidden):
+		return &resolverError{code: codeForbidden, err: event.ErrForbidden}
+	case errors.Is(err, preferences.ErrForbidden):
+		return &resolverError{code: codeForbidden, err: preferences.ErrForbidden}
+	case errors.Is(err, auth.ErrInsufficientScope):
+		return &resolverError{code: codeForbidden, err: auth.ErrInsufficientScope}
+	default:
+		return &resolverError{code: codeInternal, err: errInternal}
+	}
+}
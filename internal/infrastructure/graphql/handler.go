@@ -0,0 +1,80 @@
+// Package gql предоставляет GraphQL API над event.Service: выборки событий
+// за диапазоны вместе с календарями и участниками и мутации событий
+package gql
+
+import (
+	"calendar/internal/event"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/request"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/preferences"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	_ "embed"
+	"log/slog"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const maxQueryDepth = 10
+
+//go:embed schema.graphql
+var schema string
+
+// GraphQLRequest тело POST /graphql
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// NewHandler создает обработчик POST /graphql. Схема разбирается один раз,
+// загрузчики создаются на каждый запрос
+func NewHandler(log *slog.Logger, svc event.Service, prefs preferences.Service) http.HandlerFunc {
+	s := graphql.MustParseSchema(schema, &resolver{svc: svc},
+		graphql.MaxDepth(maxQueryDepth),
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.graphql"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		var req GraphQLRequest
+
+		if err := request.DecodeJSON(w, r, &req); err != nil {
+			log.Error("bad request",
+				slog.String("type", request.Message(err)),
+				sl.Err(err),
+			)
+			response.Error(w, r, err)
+			return
+		}
+
+		ctx := withLoaders(r.Context(), newLoaders(r.Context(), svc, prefs))
+		ctx = withLang(ctx, valResp.Negotiate(r.Header.Get("Accept-Language")))
+		res := s.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+		if len(res.Errors) > 0 {
+			log.Error("graphql request failed",
+				slog.String("operation", req.OperationName),
+				slog.Any("errors", res.Errors),
+			)
+		} else {
+			log.Info("graphql request handled", slog.String("operation", req.OperationName))
+		}
+
+		// по соглашению GraphQL ошибки резолверов возвращаются в теле со статусом 200
+		response.WriteJSON(w, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,112 @@
+package gql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLoaderWait     = 2 * time.Millisecond
+	defaultLoaderMaxBatch = 100
+)
+
+// BatchFunc загружает значения сразу для нескольких ключей. Ключи, которых
+// нет в результате, получают нулевое значение
+type BatchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// Loader собирает ключи, запрошенные параллельными резолверами в течение
+// короткого окна, и загружает их одним вызовом BatchFunc. Результаты
+// кешируются на время жизни загрузчика, то есть одного запроса
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[K]*thunk[V]
+	pending *batch[K, V]
+}
+
+type thunk[V any] struct {
+	done chan struct{}
+	val  V
+	err  error
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	thunks []*thunk[V]
+	sent   bool
+}
+
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     defaultLoaderWait,
+		maxBatch: defaultLoaderMaxBatch,
+		cache:    make(map[K]*thunk[V]),
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	t, ok := l.cache[key]
+	if !ok {
+		t = &thunk[V]{done: make(chan struct{})}
+		l.cache[key] = t
+		l.enqueue(key, t)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return t.val, t.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue вызывается под l.mu
+func (l *Loader[K, V]) enqueue(key K, t *thunk[V]) {
+	if l.pending == nil {
+		b := &batch[K, V]{}
+		l.pending = b
+		time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.thunks = append(b.thunks, t)
+	if len(b.keys) >= l.maxBatch {
+		b.sent = true
+		l.pending = nil
+		go l.run(b)
+	}
+}
+
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if b.sent {
+		l.mu.Unlock()
+		return
+	}
+	b.sent = true
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	vals, err := l.fetch(b.keys)
+	for i, t := range b.thunks {
+		if err != nil {
+			t.err = err
+		} else {
+			t.val = vals[b.keys[i]]
+		}
+		close(t.done)
+	}
+}
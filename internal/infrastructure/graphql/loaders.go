@@ -0,0 +1,116 @@
+package gql
+
+import (
+	"calendar/internal/event"
+	"calendar/internal/preferences"
+	valResp "calendar/pkg/validator"
+
+	"context"
+	"errors"
+	"time"
+)
+
+type ctxKey string
+
+const (
+	loadersKey ctxKey = "loaders"
+	langKey    ctxKey = "lang"
+)
+
+// rangeKey выборка событий за [From, To), ненулевой CalendarUUID или
+// UserUUID сужает ее до одного календаря или пользователя.
+// Время хранится в UnixNano, чтобы ключ сравнивался без учета часового пояса
+type rangeKey struct {
+	From         int64
+	To           int64
+	CalendarUUID uint64
+	UserUUID     uint64
+}
+
+func newRangeKey(from, to time.Time) rangeKey {
+	return rangeKey{From: from.UnixNano(), To: to.UnixNano()}
+}
+
+func (k rangeKey) match(e event.Event) bool {
+	if k.CalendarUUID != 0 && e.CalendarUUID != k.CalendarUUID {
+		return false
+	}
+	if k.UserUUID != 0 && e.UserUUID != k.UserUUID {
+		return false
+	}
+	return true
+}
+
+// loaders загрузчики одного запроса, через них резолверы вложенных полей
+// избегают N+1 обращений к сервисам
+type loaders struct {
+	events   *Loader[rangeKey, []event.Event]
+	profiles *Loader[uint64, preferences.Profile]
+}
+
+// newLoaders ctx запроса нужен загрузчику событий для проверок доступа,
+// а загрузчику профилей для организации
+func newLoaders(ctx context.Context, svc event.Service, prefs preferences.Service) *loaders {
+	return &loaders{
+		events:   NewLoader(eventsBatch(ctx, svc)),
+		profiles: NewLoader(profilesBatch(ctx, prefs)),
+	}
+}
+
+// profilesBatch профили организации запроса одним GetMany
+func profilesBatch(ctx context.Context, prefs preferences.Service) BatchFunc[uint64, preferences.Profile] {
+	return func(users []uint64) (map[uint64]preferences.Profile, error) {
+		return prefs.GetMany(ctx, users)
+	}
+}
+
+// eventsBatch делает один ListByRange на каждый различный диапазон и
+// раскладывает результат по календарям и пользователям из ключей
+func eventsBatch(ctx context.Context, svc event.Service) BatchFunc[rangeKey, []event.Event] {
+	return func(keys []rangeKey) (map[rangeKey][]event.Event, error) {
+		byRange := make(map[rangeKey][]event.Event)
+		res := make(map[rangeKey][]event.Event, len(keys))
+
+		for _, k := range keys {
+			rk := rangeKey{From: k.From, To: k.To}
+			all, ok := byRange[rk]
+			if !ok {
+				var err error
+				all, err = svc.ListByRange(ctx, time.Unix(0, k.From), time.Unix(0, k.To))
+				if err != nil && !errors.Is(err, event.ErrNotFound) {
+					return nil, err
+				}
+				byRange[rk] = all
+			}
+
+			events := []event.Event{}
+			for _, e := range all {
+				if k.match(e) {
+					events = append(events, e)
+				}
+			}
+			res[k] = events
+		}
+		return res, nil
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey).(*loaders)
+}
+
+// withLang сохраняет язык сообщений валидации, выбранный по Accept-Language
+func withLang(ctx context.Context, lang valResp.Lang) context.Context {
+	return context.WithValue(ctx, langKey, lang)
+}
+
+func langFrom(ctx context.Context) valResp.Lang {
+	if lang, ok := ctx.Value(langKey).(valResp.Lang); ok {
+		return lang
+	}
+	return valResp.DefaultLang
+}
@@ -0,0 +1,220 @@
+package gql
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	valResp "calendar/pkg/validator"
+
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// resolver корневой резолвер запросов и мутаций
+type resolver struct {
+	svc event.Service
+}
+
+type eventInput struct {
+	UserUUID     *graphql.ID
+	CalendarUUID *graphql.ID
+	Date         graphql.Time
+	End          *graphql.Time
+	Title        string
+	Description  string
+	Reminders    *[]reminderInput
+}
+
+type reminderInput struct {
+	MinutesBefore int32
+}
+
+// toEvent проверяет ввод по тем же правилам, что и dto.AddEventRequest
+// в HTTP обработчиках
+func (in eventInput) toEvent(ctx context.Context) (event.Event, error) {
+	userUUID, err := parseOptionalID(in.UserUUID)
+	if err != nil {
+		return event.Event{}, err
+	}
+	calendarUUID, err := parseOptionalID(in.CalendarUUID)
+	if err != nil {
+		return event.Event{}, err
+	}
+
+	req := dto.AddEventRequest{
+		UserUUID:     userUUID,
+		CalendarUUID: calendarUUID,
+		Date:         in.Date.Time,
+		Title:        in.Title,
+		Desc:         in.Description,
+	}
+	if in.End != nil {
+		req.End = in.End.Time
+	}
+	if in.Reminders != nil {
+		for _, r := range *in.Reminders {
+			req.Reminders = append(req.Reminders, dto.Reminder{MinutesBefore: int(r.MinutesBefore)})
+		}
+	}
+
+	if err := valResp.Struct(req); err != nil {
+		var validateErr validator.ValidationErrors
+		if !errors.As(err, &validateErr) {
+			return event.Event{}, badInput(err)
+		}
+		return event.Event{}, &resolverError{
+			code:   codeBadUserInput,
+			err:    errInvalidInput,
+			fields: valResp.ValidationErrorLang(validateErr, langFrom(ctx)).Errors,
+		}
+	}
+	e := req.ToEvent()
+	// владелец из токена, как в HTTP обработчиках
+	if id, ok := auth.FromContext(ctx); ok {
+		e.UserUUID = id.UserUUID
+	}
+	return e, nil
+}
+
+func (r *resolver) Events(ctx context.Context, args struct {
+	From         graphql.Time
+	To           graphql.Time
+	CalendarUUID *graphql.ID
+	UserUUID     *graphql.ID
+}) ([]*eventResolver, error) {
+	key, err := rangeArgs{From: args.From, To: args.To}.key()
+	if err != nil {
+		return nil, err
+	}
+	if key.CalendarUUID, err = parseOptionalID(args.CalendarUUID); err != nil {
+		return nil, err
+	}
+	if key.UserUUID, err = parseOptionalID(args.UserUUID); err != nil {
+		return nil, err
+	}
+
+	events, err := loadersFrom(ctx).events.Load(ctx, key)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return newEventResolvers(events), nil
+}
+
+type dateArgs struct {
+	Date graphql.Time
+}
+
+func (r *resolver) EventsForDay(ctx context.Context, args dateArgs) ([]*eventResolver, error) {
+	return r.list(ctx, args.Date.Time, r.svc.ListByDay)
+}
+
+func (r *resolver) EventsForWeek(ctx context.Context, args dateArgs) ([]*eventResolver, error) {
+	return r.list(ctx, args.Date.Time, r.svc.ListByWeek)
+}
+
+func (r *resolver) EventsForMonth(ctx context.Context, args dateArgs) ([]*eventResolver, error) {
+	return r.list(ctx, args.Date.Time, r.svc.ListByMonth)
+}
+
+func (r *resolver) list(
+	ctx context.Context,
+	t time.Time,
+	fetch func(context.Context, time.Time) ([]event.Event, error),
+) ([]*eventResolver, error) {
+	events, err := fetch(ctx, t)
+	// пустое хранилище для списков не ошибка, как и в HTTP обработчиках
+	if err != nil && !errors.Is(err, event.ErrNotFound) {
+		return nil, serviceError(err)
+	}
+	return newEventResolvers(events), nil
+}
+
+func (r *resolver) Event(ctx context.Context, args struct{ UUID graphql.ID }) (*eventResolver, error) {
+	id, err := parseID(args.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := r.svc.Get(ctx, id)
+	if errors.Is(err, event.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return &eventResolver{e: e}, nil
+}
+
+func (r *resolver) Calendar(args struct{ UUID graphql.ID }) (*calendarResolver, error) {
+	id, err := parseID(args.UUID)
+	if err != nil {
+		return nil, err
+	}
+	return &calendarResolver{uuid: id}, nil
+}
+
+func (r *resolver) Attendee(args struct{ UserUUID graphql.ID }) (*attendeeResolver, error) {
+	id, err := parseID(args.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+	return &attendeeResolver{uuid: id}, nil
+}
+
+func (r *resolver) CreateEvent(ctx context.Context, args struct{ Input eventInput }) (*eventResolver, error) {
+	if err := auth.Require(ctx, auth.ScopeWrite); err != nil {
+		return nil, serviceError(err)
+	}
+	e, err := args.Input.toEvent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := r.svc.Add(ctx, e)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	e.UUID = id
+	return &eventResolver{e: e}, nil
+}
+
+func (r *resolver) UpdateEvent(ctx context.Context, args struct {
+	UUID  graphql.ID
+	Input eventInput
+}) (*eventResolver, error) {
+	if err := auth.Require(ctx, auth.ScopeWrite); err != nil {
+		return nil, serviceError(err)
+	}
+	id, err := parseID(args.UUID)
+	if err != nil {
+		return nil, err
+	}
+	e, err := args.Input.toEvent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e.UUID = id
+	if err := r.svc.Update(ctx, e); err != nil {
+		return nil, serviceError(err)
+	}
+	return &eventResolver{e: e}, nil
+}
+
+func (r *resolver) DeleteEvent(ctx context.Context, args struct{ UUID graphql.ID }) (graphql.ID, error) {
+	if err := auth.Require(ctx, auth.ScopeWrite); err != nil {
+		return "", serviceError(err)
+	}
+	id, err := parseID(args.UUID)
+	if err != nil {
+		return "", err
+	}
+	if err := r.svc.Delete(ctx, id); err != nil {
+		return "", serviceError(err)
+	}
+	return args.UUID, nil
+}
@@ -0,0 +1,203 @@
+package gql
+
+import (
+	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/preferences"
+
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type rangeArgs struct {
+	From graphql.Time
+	To   graphql.Time
+}
+
+func (a rangeArgs) key() (rangeKey, error) {
+	if !a.To.After(a.From.Time) {
+		return rangeKey{}, badInput(errInvalidRange)
+	}
+	return newRangeKey(a.From.Time, a.To.Time), nil
+}
+
+func toID(id uint64) graphql.ID {
+	return graphql.ID(strconv.FormatUint(id, 10))
+}
+
+func parseID(id graphql.ID) (uint64, error) {
+	res, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, badInput(errInvalidID)
+	}
+	return res, nil
+}
+
+func parseOptionalID(id *graphql.ID) (uint64, error) {
+	if id == nil {
+		return 0, nil
+	}
+	return parseID(*id)
+}
+
+type eventResolver struct {
+	e event.Event
+}
+
+func newEventResolvers(events []event.Event) []*eventResolver {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Date.Equal(events[j].Date) {
+			return events[i].UUID < events[j].UUID
+		}
+		return events[i].Date.Before(events[j].Date)
+	})
+
+	res := make([]*eventResolver, 0, len(events))
+	for _, e := range events {
+		res = append(res, &eventResolver{e: e})
+	}
+	return res
+}
+
+func (r *eventResolver) UUID() graphql.ID {
+	return toID(r.e.UUID)
+}
+
+func (r *eventResolver) Date() graphql.Time {
+	return graphql.Time{Time: r.e.Date}
+}
+
+func (r *eventResolver) End() graphql.Time {
+	return graphql.Time{Time: r.e.EndTime()}
+}
+
+func (r *eventResolver) Title() string {
+	return r.e.Title
+}
+
+func (r *eventResolver) Description() string {
+	return r.e.Desc
+}
+
+func (r *eventResolver) Reminders() []*reminderResolver {
+	res := make([]*reminderResolver, 0, len(r.e.Reminders))
+	for _, rem := range r.e.Reminders {
+		res = append(res, &reminderResolver{r: rem})
+	}
+	return res
+}
+
+func (r *eventResolver) Calendar() *calendarResolver {
+	return &calendarResolver{uuid: r.e.CalendarUUID}
+}
+
+// Attendees пока состоит из владельца события, отдельного списка
+// участников в модели нет
+func (r *eventResolver) Attendees() []*attendeeResolver {
+	if r.e.UserUUID == 0 {
+		return []*attendeeResolver{}
+	}
+	return []*attendeeResolver{{uuid: r.e.UserUUID}}
+}
+
+type reminderResolver struct {
+	r event.Reminder
+}
+
+func (r *reminderResolver) MinutesBefore() int32 {
+	return int32(r.r.Before / time.Minute)
+}
+
+type calendarResolver struct {
+	uuid uint64
+}
+
+func (r *calendarResolver) UUID() graphql.ID {
+	return toID(r.uuid)
+}
+
+func (r *calendarResolver) Events(ctx context.Context, args rangeArgs) ([]*eventResolver, error) {
+	key, err := args.key()
+	if err != nil {
+		return nil, err
+	}
+	key.CalendarUUID = r.uuid
+
+	events, err := loadersFrom(ctx).events.Load(ctx, key)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return newEventResolvers(events), nil
+}
+
+type attendeeResolver struct {
+	uuid uint64
+}
+
+func (r *attendeeResolver) UserUUID() graphql.ID {
+	return toID(r.uuid)
+}
+
+// profile профиль участника по тому же правилу, что и в REST: только свой,
+// см. preferences.Owner. Загрузчик вызывает GetMany без этой проверки
+func (r *attendeeResolver) profile(ctx context.Context) (preferences.Profile, error) {
+	if _, err := preferences.Owner(ctx, r.uuid); err != nil {
+		return preferences.Profile{}, err
+	}
+	return loadersFrom(ctx).profiles.Load(ctx, r.uuid)
+}
+
+func (r *attendeeResolver) TimeZone(ctx context.Context) (string, error) {
+	p, err := r.profile(ctx)
+	if err != nil {
+		return "", serviceError(err)
+	}
+	return p.Location().String(), nil
+}
+
+func (r *attendeeResolver) WorkingHours(ctx context.Context) ([]*dayHoursResolver, error) {
+	p, err := r.profile(ctx)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	res := make([]*dayHoursResolver, 0, len(p.WorkingHours))
+	for _, h := range dto.FromProfile(p).WorkingHours {
+		res = append(res, &dayHoursResolver{h: h})
+	}
+	return res, nil
+}
+
+func (r *attendeeResolver) Events(ctx context.Context, args rangeArgs) ([]*eventResolver, error) {
+	key, err := args.key()
+	if err != nil {
+		return nil, err
+	}
+	key.UserUUID = r.uuid
+
+	events, err := loadersFrom(ctx).events.Load(ctx, key)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return newEventResolvers(events), nil
+}
+
+type dayHoursResolver struct {
+	h dto.DayHours
+}
+
+func (r *dayHoursResolver) Weekday() int32 {
+	return int32(r.h.Weekday)
+}
+
+func (r *dayHoursResolver) Start() string {
+	return r.h.Start
+}
+
+func (r *dayHoursResolver) End() string {
+	return r.h.End
+}
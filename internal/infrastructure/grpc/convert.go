@@ -0,0 +1,56 @@
+package grpcserver
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	calendarv1 "calendar/pkg/api/calendar/v1"
+
+	"context"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toEvent(e *calendarv1.Event) event.Event {
+	res := event.Event{
+		UUID:         e.GetUuid(),
+		UserUUID:     e.GetUserUuid(),
+		CalendarUUID: e.GetCalendarUuid(),
+		Date:         e.GetDate().AsTime(),
+		Title:        e.GetTitle(),
+		Desc:         e.GetDescription(),
+	}
+	if e.GetEnd() != nil {
+		res.End = e.GetEnd().AsTime()
+	}
+	for _, r := range e.GetReminders() {
+		res.Reminders = append(res.Reminders, event.Reminder{Before: r.GetBefore().AsDuration()})
+	}
+	return res
+}
+
+func fromEvent(e event.Event) *calendarv1.Event {
+	res := &calendarv1.Event{
+		Uuid:         e.UUID,
+		UserUuid:     e.UserUUID,
+		CalendarUuid: e.CalendarUUID,
+		Date:         timestamppb.New(e.Date),
+		Title:        e.Title,
+		Description:  e.Desc,
+	}
+	if !e.End.IsZero() {
+		res.End = timestamppb.New(e.End)
+	}
+	for _, r := range e.Reminders {
+		res.Reminders = append(res.Reminders, &calendarv1.Reminder{Before: durationpb.New(r.Before)})
+	}
+	return res
+}
+
+// ownByCaller подставляет владельца нового события из токена вместо
+// user_uuid из запроса, если вызов аутентифицирован
+func ownByCaller(ctx context.Context, e *event.Event) {
+	if id, ok := auth.FromContext(ctx); ok {
+		e.UserUUID = id.UserUUID
+	}
+}
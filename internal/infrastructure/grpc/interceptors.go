@@ -0,0 +1,183 @@
+package grpcserver
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/ratelimit"
+	"calendar/internal/tenant"
+	"calendar/pkg/sl_logger/sl"
+
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDHeader совпадает с HTTP заголовком, чтобы ID сквозь сервисы был один
+const requestIDHeader = "x-request-id"
+
+type ctxKey string
+
+const requestIDKey ctxKey = "requestID"
+
+// RequestID берет ID запроса из метаданных x-request-id или генерирует новый
+// и возвращает его клиенту в заголовке ответа
+func RequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var reqID string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get(requestIDHeader); len(vals) > 0 {
+				reqID = vals[0]
+			}
+		}
+		if reqID == "" {
+			reqID = uuid.New().String()
+		}
+
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, reqID))
+		return handler(context.WithValue(ctx, requestIDKey, reqID), req)
+	}
+}
+
+func GetRequestID(ctx context.Context) string {
+	if val, ok := ctx.Value(requestIDKey).(string); ok {
+		return val
+	}
+	return ""
+}
+
+// Logger пишет строку на каждый вызов по аналогии с middleware.NewMWLogger
+func Logger(log *slog.Logger) grpc.UnaryServerInterceptor {
+	log = log.With(slog.String("component", "grpc/logger"))
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		log.Info("request completed",
+			slog.String("method", info.FullMethod),
+			slog.String("code", status.Code(err).String()),
+			slog.String("request_id", GetRequestID(ctx)),
+			slog.String("duration", time.Since(start).String()),
+		)
+		return resp, err
+	}
+}
+
+// TokenVerifier проверяет учетные данные одной схемы, как
+// middleware.TokenVerifier в HTTP
+type TokenVerifier interface {
+	Verify(token string) (auth.Identity, error)
+}
+
+// Auth требует метаданные authorization: <scheme> <credentials> со схемой
+// из verifiers, например Bearer или ApiKey, и кладет пользователя в
+// контекст. Без verifiers вызовы пропускаются как есть, с anonymous
+// пропускаются вызовы без метаданных authorization, как middleware.Optional
+func Auth(log *slog.Logger, verifiers map[string]TokenVerifier, anonymous bool) grpc.UnaryServerInterceptor {
+	log = log.With(slog.String("component", "grpc/auth"))
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if len(verifiers) == 0 {
+			return handler(ctx, req)
+		}
+
+		var header string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get("authorization"); len(vals) > 0 {
+				header = vals[0]
+			}
+		}
+		if header == "" && anonymous {
+			return handler(ctx, req)
+		}
+		scheme, creds, _ := strings.Cut(header, " ")
+
+		var verifier TokenVerifier
+		for s, v := range verifiers {
+			if strings.EqualFold(s, scheme) {
+				verifier = v
+			}
+		}
+		if verifier == nil || strings.TrimSpace(creds) == "" {
+			return nil, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+		}
+
+		id, err := verifier.Verify(strings.TrimSpace(creds))
+		if err != nil {
+			log.Warn("authentication failed",
+				slog.String("method", info.FullMethod),
+				slog.String("request_id", GetRequestID(ctx)),
+				sl.Err(err),
+			)
+			return nil, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+		}
+		return handler(tenant.WithOrg(auth.WithIdentity(ctx, id), id.OrgUUID), req)
+	}
+}
+
+// RateLimitPolicy лимит вызовов на клиента, KeyBy одно из
+// ratelimit.KeyByAPIKey, KeyByUser, KeyByIP. Без Limiter лимита нет
+type RateLimitPolicy struct {
+	Limiter ratelimit.Limiter
+	KeyBy   string
+	Limit   ratelimit.Limit
+}
+
+// RateLimit отвечает ResourceExhausted с трейлером retry-after, когда у
+// клиента кончились токены. Клиент определяется как в HTTP, поэтому при
+// общем Limiter вызовы gRPC и HTTP запросы тратят одну корзину. Политика с
+// ratelimit.KeyByIP ставится до Auth, чтобы вызовы с неверными учетными
+// данными тоже ограничивались
+func RateLimit(log *slog.Logger, policy RateLimitPolicy) grpc.UnaryServerInterceptor {
+	log = log.With(slog.String("component", "grpc/rate_limit"))
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if policy.Limiter == nil || policy.Limit.Unlimited() {
+			return handler(ctx, req)
+		}
+
+		key := clientKey(ctx, policy.KeyBy)
+		d := policy.Limiter.Allow(key, policy.Limit)
+		if !d.Allowed {
+			log.Warn("rate limit exceeded",
+				slog.String("method", info.FullMethod),
+				slog.String("request_id", GetRequestID(ctx)),
+				slog.String("key", key),
+			)
+			retry := max(d.RetryAfter, time.Second)
+			grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(retry.Seconds())))))
+			return nil, status.Error(codes.ResourceExhausted, ratelimit.ErrLimited.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+func clientKey(ctx context.Context, keyBy string) string {
+	id, ok := auth.FromContext(ctx)
+	if ok && keyBy == ratelimit.KeyByAPIKey && id.Method == "api_key" {
+		return id.Subject
+	}
+	if ok && keyBy != ratelimit.KeyByIP {
+		return fmt.Sprintf("user:%d:%d", id.OrgUUID, id.UserUUID)
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "ip:unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,241 @@
+// Package grpcserver предоставляет gRPC API календаря поверх event.Service
+package grpcserver
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	"calendar/internal/tenant"
+	calendarv1 "calendar/pkg/api/calendar/v1"
+	"calendar/pkg/sl_logger/sl"
+
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errMissingEvent = errors.New("event is required")
+	errMissingUUID  = errors.New("uuid is required")
+	errMissingDate  = errors.New("date is required")
+	errMissingTitle = errors.New("title is required")
+	errMissingDesc  = errors.New("description is required")
+	errInvalidEnd   = errors.New("end must be after date")
+	errNegativeRem  = errors.New("reminder must not be negative")
+)
+
+// Server реализует calendarv1.CalendarServiceServer, разделяя event.Service
+// с HTTP обработчиками
+type Server struct {
+	calendarv1.UnimplementedCalendarServiceServer
+
+	log *slog.Logger
+	svc event.Service
+}
+
+// New создает gRPC сервер с интерсепторами request ID, логирования,
+// аутентификации и лимитов: preAuth по адресу до аутентификации, limit по
+// клиенту после нее. anonymous пропускает вызовы без учетных данных, см.
+// Auth. Регистрирует в нем CalendarService и reflection
+func New(log *slog.Logger, svc event.Service, verifiers map[string]TokenVerifier, anonymous bool, preAuth, limit RateLimitPolicy) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			RequestID(),
+			Logger(log),
+			RateLimit(log, preAuth),
+			Auth(log, verifiers, anonymous),
+			RateLimit(log, limit),
+		),
+	)
+	calendarv1.RegisterCalendarServiceServer(srv, &Server{log: log, svc: svc})
+	reflection.Register(srv)
+	return srv
+}
+
+func (s *Server) Add(ctx context.Context, req *calendarv1.AddRequest) (*calendarv1.AddResponse, error) {
+	const op = "grpc.event.add"
+	log := s.with(ctx, op)
+
+	if err := auth.Require(ctx, auth.ScopeWrite); err != nil {
+		log.Error("permission denied", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	if err := validateEvent(req.GetEvent(), false); err != nil {
+		log.Error("invalid request", sl.Err(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	e := toEvent(req.GetEvent())
+	e.UUID = 0
+	ownByCaller(ctx, &e)
+	id, err := s.svc.Add(ctx, e)
+	if err != nil {
+		log.Error("failed to add event", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	log.Info("event added", slog.Uint64("uuid", id))
+	return &calendarv1.AddResponse{Uuid: id}, nil
+}
+
+func (s *Server) Update(ctx context.Context, req *calendarv1.UpdateRequest) (*calendarv1.UpdateResponse, error) {
+	const op = "grpc.event.update"
+	log := s.with(ctx, op)
+
+	if err := auth.Require(ctx, auth.ScopeWrite); err != nil {
+		log.Error("permission denied", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	if err := validateEvent(req.GetEvent(), true); err != nil {
+		log.Error("invalid request", sl.Err(err))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	e := toEvent(req.GetEvent())
+	if err := s.svc.Update(ctx, e); err != nil {
+		log.Error("failed to update event", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	log.Info("event updated", slog.Uint64("uuid", req.GetEvent().GetUuid()))
+	return &calendarv1.UpdateResponse{}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *calendarv1.DeleteRequest) (*calendarv1.DeleteResponse, error) {
+	const op = "grpc.event.delete"
+	log := s.with(ctx, op)
+
+	if err := auth.Require(ctx, auth.ScopeWrite); err != nil {
+		log.Error("permission denied", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	if req.GetUuid() == 0 {
+		log.Error("invalid request", sl.Err(errMissingUUID))
+		return nil, status.Error(codes.InvalidArgument, errMissingUUID.Error())
+	}
+
+	if err := s.svc.Delete(ctx, req.GetUuid()); err != nil {
+		log.Error("failed to delete event", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	log.Info("event deleted", slog.Uint64("uuid", req.GetUuid()))
+	return &calendarv1.DeleteResponse{}, nil
+}
+
+func (s *Server) Get(ctx context.Context, req *calendarv1.GetRequest) (*calendarv1.GetResponse, error) {
+	const op = "grpc.event.get"
+	log := s.with(ctx, op)
+
+	if req.GetUuid() == 0 {
+		log.Error("invalid request", sl.Err(errMissingUUID))
+		return nil, status.Error(codes.InvalidArgument, errMissingUUID.Error())
+	}
+
+	e, err := s.svc.Get(ctx, req.GetUuid())
+	if err != nil {
+		log.Error("failed to get event", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	return &calendarv1.GetResponse{Event: fromEvent(e)}, nil
+}
+
+func (s *Server) ListByDay(ctx context.Context, req *calendarv1.ListRequest) (*calendarv1.ListResponse, error) {
+	return s.list(ctx, "grpc.event.list_by_day", req, s.svc.ListByDay)
+}
+
+func (s *Server) ListByWeek(ctx context.Context, req *calendarv1.ListRequest) (*calendarv1.ListResponse, error) {
+	return s.list(ctx, "grpc.event.list_by_week", req, s.svc.ListByWeek)
+}
+
+func (s *Server) ListByMonth(ctx context.Context, req *calendarv1.ListRequest) (*calendarv1.ListResponse, error) {
+	return s.list(ctx, "grpc.event.list_by_month", req, s.svc.ListByMonth)
+}
+
+func (s *Server) list(
+	ctx context.Context,
+	op string,
+	req *calendarv1.ListRequest,
+	fetch func(context.Context, time.Time) ([]event.Event, error),
+) (*calendarv1.ListResponse, error) {
+	log := s.with(ctx, op)
+
+	if req.GetDate() == nil {
+		log.Error("invalid request", sl.Err(errMissingDate))
+		return nil, status.Error(codes.InvalidArgument, errMissingDate.Error())
+	}
+
+	events, err := fetch(ctx, req.GetDate().AsTime())
+	// пустое хранилище для списков не ошибка, как и в HTTP обработчиках
+	if err != nil && !errors.Is(err, event.ErrNotFound) {
+		log.Error("failed to list events", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	res := &calendarv1.ListResponse{Events: make([]*calendarv1.Event, 0, len(events))}
+	for _, e := range events {
+		res.Events = append(res.Events, fromEvent(e))
+	}
+	return res, nil
+}
+
+func (s *Server) with(ctx context.Context, op string) *slog.Logger {
+	return s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", GetRequestID(ctx)),
+	)
+}
+
+func validateEvent(e *calendarv1.Event, update bool) error {
+	switch {
+	case e == nil:
+		return errMissingEvent
+	case update && e.GetUuid() == 0:
+		return errMissingUUID
+	case e.GetDate() == nil:
+		return errMissingDate
+	case e.GetTitle() == "":
+		return errMissingTitle
+	case e.GetDescription() == "":
+		return errMissingDesc
+	case e.GetEnd() != nil && !e.GetEnd().AsTime().After(e.GetDate().AsTime()):
+		return errInvalidEnd
+	}
+	for _, r := range e.GetReminders() {
+		if r.GetBefore().AsDuration() < 0 {
+			return errNegativeRem
+		}
+	}
+	return nil
+}
+
+// toStatus переводит ошибки сервиса в коды gRPC
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+	case errors.Is(err, auth.ErrInsufficientScope):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, event.ErrNotFound):
+		return status.Error(codes.NotFound, event.ErrNotFound.Error())
+	case errors.Is(err, event.ErrConflict):
+		return status.Error(codes.AlreadyExists, event.ErrConflict.Error())
+	case errors.Is(err, event.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, event.ErrForbidden):
+		return status.Error(codes.PermissionDenied, event.ErrForbidden.Error())
+	case errors.Is(err, tenant.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, tenant.ErrQuotaExceeded.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"calendar/internal/apikey"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewAddAPIKeyHandler создает обработчик POST /admin/create_api_key.
+// Открытый текст ключа возвращается только в этом ответе
+func NewAddAPIKeyHandler(log *slog.Logger, svc apikey.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.api_key.add"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.CreateAPIKeyRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		k, plain, err := svc.Create(r.Context(), req.UserUUID, req.Name, apikey.Scope(req.Scope))
+		if err != nil {
+			log.Error("failed to create api key", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("api key created",
+			slog.Uint64("id", k.ID),
+			slog.Uint64("user", k.UserUUID),
+			slog.String("prefix", k.Prefix),
+		)
+
+		apiKeyResponseOK(w, k, plain)
+	}
+}
+
+func apiKeyResponseOK(w http.ResponseWriter, k apikey.Key, plain string) {
+	key := dto.FromAPIKey(k, plain)
+	r := dto.APIKeyResponse{
+		ValidationResponse: valResp.OK(),
+		APIKey:             &key,
+	}
+	response.WriteJSON(w, http.StatusOK, r)
+}
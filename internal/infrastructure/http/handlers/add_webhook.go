@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/webhook"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewAddWebhookHandler создает обработчик POST /create_webhook.
+// Секрет для проверки подписи возвращается только в этом ответе
+func NewAddWebhookHandler(log *slog.Logger, svc webhook.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.webhook.add"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.AddWebhookRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		sub := webhook.Subscription{URL: req.URL, Secret: req.Secret}
+		for _, t := range req.Types {
+			sub.Types = append(sub.Types, event.ChangeType(t))
+		}
+
+		sub, err := svc.Subscribe(r.Context(), sub)
+		if err != nil {
+			log.Error("failed to add webhook", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("webhook added", slog.Uint64("id", sub.ID))
+
+		addWebhookResponseOK(w, sub)
+	}
+}
+
+func addWebhookResponseOK(w http.ResponseWriter, sub webhook.Subscription) {
+	wh := dto.FromSubscription(sub, true)
+	r := dto.AddWebhookResponse{
+		ValidationResponse: valResp.OK(),
+		Webhook:            &wh,
+	}
+	response.WriteJSON(w, http.StatusOK, r)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/preferences"
+	"calendar/pkg/sl_logger/sl"
+
+	"log/slog"
+	"net/http"
+)
+
+// ownByCaller подставляет владельца нового события из токена вместо
+// userUUID из тела. Без аутентификации событие остается как есть. При
+// обновлении владельца сохраняет event.Service
+func ownByCaller(r *http.Request, e *event.Event) {
+	if id, ok := middleware.GetIdentity(r); ok {
+		e.UserUUID = id.UserUUID
+	}
+}
+
+// ownByIdentity то же для WebSocket, где пользователь известен с handshake
+func ownByIdentity(id *auth.Identity, e *event.Event) {
+	if id != nil {
+		e.UserUUID = id.UserUUID
+	}
+}
+
+// profileOwner пользователь, чей профиль доступности читается или меняется,
+// см. preferences.Owner. Без аутентификации user обязателен, чужой профиль
+// дает 403. При ошибке ответ уже записан
+func profileOwner(log *slog.Logger, w http.ResponseWriter, r *http.Request, user uint64) (uint64, bool) {
+	if _, ok := middleware.GetIdentity(r); !ok && user == 0 {
+		log.Error("bad request", slog.String("type", errMissingUserParam.Error()))
+		response.BadRequest(w, r, errMissingUserParam)
+		return 0, false
+	}
+	owner, err := preferences.Owner(r.Context(), user)
+	if err != nil {
+		log.Error("foreign profile", sl.Err(err))
+		response.Error(w, r, err)
+		return 0, false
+	}
+	return owner, true
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"calendar/internal/acl"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// NewCalendarACLHandler создает обработчик GET /calendar_acl?calendar=<UUID>,
+// список доступа виден только владельцам календаря
+func NewCalendarACLHandler(log *slog.Logger, svc acl.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.acl.list"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		calendar, err := strconv.ParseUint(r.URL.Query().Get("calendar"), 10, 64)
+		if err != nil || calendar == 0 {
+			log.Error("bad request", slog.String("type", errInvalidCalendarParam.Error()))
+			response.BadRequest(w, r, errInvalidCalendarParam)
+			return
+		}
+
+		grants, err := svc.List(r.Context(), calendar)
+		if err != nil {
+			log.Error("failed to list calendar acl", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		res := dto.CalendarACLResponse{
+			ValidationResponse: valResp.OK(),
+			Grants:             make([]dto.CalendarGrant, 0, len(grants)),
+		}
+		for _, g := range grants {
+			res.Grants = append(res.Grants, dto.FromGrant(g))
+		}
+
+		log.Info("calendar acl listed", slog.Int("count", len(res.Grants)))
+		response.WriteJSON(w, http.StatusOK, res)
+	}
+}
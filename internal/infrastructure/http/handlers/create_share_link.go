@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/sharelink"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// sharedPath маршрут публичной ленты, см. NewSharedEventsHandler
+const sharedPath = "/shared"
+
+// NewCreateShareLinkHandler создает обработчик POST /create_share_link.
+// Токен и url ленты возвращаются один раз, потом их получить нельзя
+func NewCreateShareLinkHandler(log *slog.Logger, svc sharelink.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.share_link.create"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.CreateShareLinkRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		l, token, err := svc.Create(r.Context(), req.ToLink())
+		if err != nil {
+			log.Error("failed to create share link", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("share link created", slog.Uint64("id", l.ID), slog.Uint64("calendar", l.CalendarUUID))
+
+		link := dto.FromShareLink(l, token, sharedPath+"?token="+url.QueryEscape(token))
+		response.WriteJSON(w, http.StatusOK, dto.ShareLinkResponse{
+			ValidationResponse: valResp.OK(),
+			ShareLink:          &link,
+		})
+	}
+}
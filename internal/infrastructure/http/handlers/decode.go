@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"calendar/internal/infrastructure/http/request"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// decodeRequest декодирует тело в T через request.DecodeJSON и валидирует
+// его по тегам validate, язык сообщений берется из Accept-Language. При ошибке сам пишет problem+json ответ и
+// возвращает false
+func decodeRequest[T any](log *slog.Logger, w http.ResponseWriter, r *http.Request) (T, bool) {
+	var req T
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		log.Error("bad request",
+			slog.String("type", request.Message(err)),
+			sl.Err(err),
+		)
+		response.Error(w, r, err)
+		return req, false
+	}
+
+	log.Info("request body decoded", slog.Any("req", req))
+
+	if errResp, err := validateRequest(req, valResp.Negotiate(r.Header.Get("Accept-Language"))); err != nil {
+		log.Error("invalid request", sl.Err(err))
+		response.Validation(w, r, errResp.Errors)
+		return req, false
+	}
+	return req, true
+}
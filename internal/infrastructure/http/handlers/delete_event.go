@@ -4,19 +4,12 @@ import (
 	"calendar/internal/event"
 	dto "calendar/internal/infrastructure/http/handlers/dto"
 	"calendar/internal/infrastructure/http/middleware"
-	"calendar/internal/infrastructure/http/request"
 	"calendar/internal/infrastructure/http/response"
-	inmem "calendar/internal/infrastructure/storage/in_memory"
 	"calendar/pkg/sl_logger/sl"
 	valResp "calendar/pkg/validator"
 
-	"encoding/json"
-	"errors"
-	"io"
 	"log/slog"
 	"net/http"
-
-	"github.com/go-playground/validator"
 )
 
 
@@ -24,57 +17,28 @@ func NewDeleteEventHandler(log *slog.Logger, svc event.Service) http.HandlerFunc
 	
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			response.MethodNotAllowed(w, r, http.MethodPost)
 			return
 		}
 		
 		const op = "handlers.event.delete"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetRequestID(r)),
 		)
 
-		var req dto.DeleteEventRequest
-
-		err := json.NewDecoder(r.Body).Decode(&req)
-		if errors.Is(err, io.EOF) {
-			log.Error("bad request",
-				slog.String("type", request.ErrEmptyReqBody.Error()),
-				sl.Err(err),
-			)
-			deleteEventResponse(w, request.ErrEmptyReqBody.Error())
-			return
-		}
-		if err != nil {
-			log.Error("bad request",
-				slog.String("type", request.ErrFailedToDecodeReqBody.Error()),
-				sl.Err(err),
-			)
-			deleteEventResponse(w,  request.ErrFailedToDecodeReqBody.Error())
+		req, ok := decodeRequest[dto.DeleteEventRequest](log, w, r)
+		if !ok {
 			return
 		}
 
-		log.Info("request body decoded", slog.Any("req", req))
-
-		if err := validator.New().Struct(req); err != nil {
-			validateErr := err.(validator.ValidationErrors)
-			log.Error("invalid request", sl.Err(err))
-			response.WriteJSON(w, http.StatusBadRequest, valResp.ValidationError(validateErr))
+		if err := svc.Delete(r.Context(), req.UUID); err != nil {
+			log.Error("failed to delete event", sl.Err(err))
+			response.Error(w, r, err)
 			return
 		}
 
-		if err := svc.Delete(req.UUID); err != nil {
-			switch {
-			case errors.Is(err, inmem.ErrNoValue):
-				log.Error("failed to delete event", sl.Err(err))
-				return
-			default:
-				log.Error("unexpected error adding event", sl.Err(err))
-				return
-			}
-		}
-
 		log.Info("event deleted", slog.Any("title", req.UUID))
 
 		deleteEventResponseOK(w, req.UUID)
@@ -88,10 +52,3 @@ func deleteEventResponseOK(w http.ResponseWriter, id uint64) {
 	}
 	response.WriteJSON(w, http.StatusOK, r)
 }
-
-func deleteEventResponse(w http.ResponseWriter, e string) {
-	r := dto.DeleteEventResponse{
-		ValidationResponse: valResp.Error(e),
-	}
-	response.WriteJSON(w, http.StatusBadRequest, r)
-}
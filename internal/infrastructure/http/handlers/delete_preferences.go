@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/preferences"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+func NewDeletePreferencesHandler(log *slog.Logger, svc preferences.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.preferences.delete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.DeletePreferencesRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		if req.UserUUID, ok = profileOwner(log, w, r, req.UserUUID); !ok {
+			return
+		}
+
+		if err := svc.Delete(r.Context(), req.UserUUID); err != nil {
+			log.Error("failed to delete preferences", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("preferences deleted", slog.Uint64("user", req.UserUUID))
+
+		deletePreferencesResponseOK(w, req.UserUUID)
+	}
+}
+
+func deletePreferencesResponseOK(w http.ResponseWriter, user uint64) {
+	r := dto.DeletePreferencesResponse{
+		ValidationResponse: valResp.OK(),
+		UserUUID:           user,
+	}
+	response.WriteJSON(w, http.StatusOK, r)
+}
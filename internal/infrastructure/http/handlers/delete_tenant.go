@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/tenant"
+	"calendar/internal/tenantadmin"
+	"calendar/pkg/sl_logger/sl"
+
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+var errTenantMismatch = errors.New("orgUUID does not match caller organization")
+
+// NewDeleteTenantHandler создает обработчик POST /admin/delete_tenant.
+// Удаляет все данные организации вызывающего, orgUUID в теле защищает
+// от удаления не той организации по ошибке
+func NewDeleteTenantHandler(log *slog.Logger, svc tenantadmin.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.tenant.delete"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.DeleteTenantRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		org := tenant.FromContext(r.Context())
+		if *req.OrgUUID != org {
+			log.Error("bad request", slog.String("type", errTenantMismatch.Error()))
+			response.BadRequest(w, r, errTenantMismatch)
+			return
+		}
+
+		deleted, err := svc.Delete(r.Context())
+		if err != nil {
+			log.Error("failed to delete tenant", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("tenant deleted",
+			slog.Uint64("org", org),
+			slog.Int("events", deleted.Events),
+		)
+		response.WriteJSON(w, http.StatusOK, dto.FromDeleted(org, deleted))
+	}
+}
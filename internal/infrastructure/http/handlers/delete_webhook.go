@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/webhook"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+func NewDeleteWebhookHandler(log *slog.Logger, svc webhook.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.webhook.delete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.DeleteWebhookRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := svc.Unsubscribe(r.Context(), req.ID); err != nil {
+			log.Error("failed to delete webhook", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("webhook deleted", slog.Uint64("id", req.ID))
+
+		deleteWebhookResponseOK(w, req.ID)
+	}
+}
+
+func deleteWebhookResponseOK(w http.ResponseWriter, id uint64) {
+	r := dto.DeleteWebhookResponse{
+		ValidationResponse: valResp.OK(),
+		ID:                 id,
+	}
+	response.WriteJSON(w, http.StatusOK, r)
+}
@@ -2,27 +2,68 @@
 package handlerdto
 
 import (
+	"calendar/internal/acl"
+	"calendar/internal/apikey"
 	"calendar/internal/event"
+	"calendar/internal/health"
+	"calendar/internal/preferences"
+	"calendar/internal/sharelink"
+	"calendar/internal/tenantadmin"
+	"calendar/internal/user"
+	"calendar/internal/webhook"
 	resp "calendar/pkg/validator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 )
 
+var ErrInvalidClock = errors.New("invalid time of day, expected HH:MM")
+
+type Reminder struct {
+	MinutesBefore int `json:"minutesBefore" validate:"min=0"`
+}
+
 type UserEvent struct {
-	Date  time.Time `json:"date"`
-	Title string    `json:"title"`
-	Desc  string    `json:"description"`
+	Date      time.Time  `json:"date"`
+	End       time.Time  `json:"end"`
+	Title     string     `json:"title"`
+	Desc      string     `json:"description"`
+	Reminders []Reminder `json:"reminders,omitempty"`
+	// OutsideWorkingHours заполняется только при annotate=working_hours
+	OutsideWorkingHours *bool `json:"outsideWorkingHours,omitempty"`
 }
 
+// AddEventRequest при включенной аутентификации UserUUID берется из токена,
+// значение из тела игнорируется
 type AddEventRequest struct {
-	Date  time.Time `json:"date" validate:"required"`
-	Title string    `json:"title" validate:"required"`
-	Desc  string    `json:"desc" validate:"required"`
+	UserUUID     uint64     `json:"userUUID"`
+	CalendarUUID uint64     `json:"calendarUUID"`
+	Date         time.Time  `json:"date" validate:"required"`
+	End          time.Time  `json:"end"`
+	Title        string     `json:"title" validate:"required"`
+	Desc         string     `json:"desc" validate:"required"`
+	Reminders    []Reminder `json:"reminders" validate:"dive"`
 }
 type AddEventResponse struct {
 	resp.ValidationResponse
+	UUID  uint64 `json:"UUID"`
 	Title string `json:"title"`
 }
 
+func (r AddEventRequest) ToEvent() event.Event {
+	return event.Event{
+		UserUUID:     r.UserUUID,
+		CalendarUUID: r.CalendarUUID,
+		Date:         r.Date,
+		End:          r.End,
+		Title:        r.Title,
+		Desc:         r.Desc,
+		Reminders:    ToReminders(r.Reminders),
+	}
+}
+
 type DeleteEventRequest struct {
 	UUID uint64 `json:"UUID" validate:"required"`
 }
@@ -32,12 +73,30 @@ type DeleteEventResponse struct {
 	UUID uint64 `json:"UUID" validate:"required"`
 }
 
+// UpdateEventRequest при включенной аутентификации владелец события не
+// меняется, значение userUUID из тела игнорируется
 type UpdateEventRequest struct {
-	UUID     uint64    `json:"UUID" validate:"required"`
-	UserUUID uint64    `json:"userUUID" validate:"required"`
-	Date     time.Time `json:"date" validate:"required"`
-	Title    string    `json:"title" validate:"required"`
-	Desc     string    `json:"description" validate:"required"`
+	UUID         uint64     `json:"UUID" validate:"required"`
+	UserUUID     uint64     `json:"userUUID"`
+	CalendarUUID uint64     `json:"calendarUUID"`
+	Date         time.Time  `json:"date" validate:"required"`
+	End          time.Time  `json:"end"`
+	Title        string     `json:"title" validate:"required"`
+	Desc         string     `json:"description" validate:"required"`
+	Reminders    []Reminder `json:"reminders" validate:"dive"`
+}
+
+func (r UpdateEventRequest) ToEvent() event.Event {
+	return event.Event{
+		UUID:         r.UUID,
+		UserUUID:     r.UserUUID,
+		CalendarUUID: r.CalendarUUID,
+		Date:         r.Date,
+		End:          r.End,
+		Title:        r.Title,
+		Desc:         r.Desc,
+		Reminders:    ToReminders(r.Reminders),
+	}
 }
 
 type UpdateEventResponse struct {
@@ -54,13 +113,667 @@ type GetEventResponse struct {
 	Events []UserEvent
 }
 
+type FreeBusyRequest struct {
+	Users []uint64  `json:"users" validate:"required,min=1"`
+	From  time.Time `json:"from" validate:"required"`
+	To    time.Time `json:"to" validate:"required,gtfield=From,maxduration=From 2208h"`
+}
+
+type FreeBusyResponse struct {
+	resp.ValidationResponse
+	From  time.Time        `json:"from"`
+	To    time.Time        `json:"to"`
+	Users []event.FreeBusy `json:"users"`
+}
+
+type WorkingHours struct {
+	Start    string `json:"start" validate:"required"`
+	End      string `json:"end" validate:"required"`
+	Weekdays []int  `json:"weekdays" validate:"dive,min=0,max=6"`
+	TimeZone string `json:"timeZone" validate:"omitempty,timezone"`
+}
+
+// SuggestSlotsRequest 744h в теге maxduration равны scheduling.MaxRange,
+// это проверяет TestSuggestSlotsRangeMatchesScheduler
+type SuggestSlotsRequest struct {
+	Participants     []uint64                `json:"participants" validate:"required,min=1"`
+	DurationMinutes  int                     `json:"durationMinutes" validate:"required,min=1"`
+	From             time.Time               `json:"from" validate:"required"`
+	To               time.Time               `json:"to" validate:"required,gtfield=From,future,maxduration=From 744h"`
+	WorkingHours     *WorkingHours           `json:"workingHours"`
+	ParticipantHours map[uint64]WorkingHours `json:"participantHours" validate:"dive"`
+	BufferMinutes    int                     `json:"bufferMinutes" validate:"min=0"`
+	StepMinutes      int                     `json:"stepMinutes" validate:"min=0"`
+	Limit            int                     `json:"limit" validate:"min=0,max=100"`
+	Rank             string                  `json:"rank" validate:"omitempty,oneof=earliest fragmentation"`
+}
+
+type Slot struct {
+	Start                time.Time `json:"start"`
+	End                  time.Time `json:"end"`
+	FragmentationMinutes int       `json:"fragmentationMinutes"`
+}
+
+type SuggestSlotsResponse struct {
+	resp.ValidationResponse
+	Slots []Slot `json:"slots"`
+}
+
+type DayHours struct {
+	Weekday int    `json:"weekday" validate:"min=0,max=6"`
+	Start   string `json:"start" validate:"required"`
+	End     string `json:"end" validate:"required"`
+}
+
+type OutOfOffice struct {
+	Start  time.Time `json:"start" validate:"required"`
+	End    time.Time `json:"end" validate:"required,gtfield=Start"`
+	Reason string    `json:"reason"`
+}
+
+// Range нужен правилу nooverlap для Preferences.OutOfOffice
+func (o OutOfOffice) Range() (time.Time, time.Time) {
+	return o.Start, o.End
+}
+
+// Preferences userUUID в запросе можно не указывать, тогда берется
+// пользователь токена
+type Preferences struct {
+	UserUUID     uint64        `json:"userUUID"`
+	TimeZone     string        `json:"timeZone" validate:"omitempty,timezone"`
+	WorkingHours []DayHours    `json:"workingHours" validate:"dive"`
+	OutOfOffice  []OutOfOffice `json:"outOfOffice" validate:"nooverlap,dive"`
+}
+
+type PreferencesRequest = Preferences
+
+type PreferencesResponse struct {
+	resp.ValidationResponse
+	Preferences *Preferences `json:"preferences,omitempty"`
+}
+
+// DeletePreferencesRequest без userUUID удаляется профиль пользователя токена
+type DeletePreferencesRequest struct {
+	UserUUID uint64 `json:"userUUID"`
+}
+
+type DeletePreferencesResponse struct {
+	resp.ValidationResponse
+	UserUUID uint64 `json:"userUUID"`
+}
+
+// ParseClock разбирает время суток HH:MM в смещение от полуночи, 24:00 допустимо
+func ParseClock(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidClock, s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func FormatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+func ToProfile(p Preferences) (preferences.Profile, error) {
+	res := preferences.Profile{
+		UserUUID:     p.UserUUID,
+		TimeZone:     p.TimeZone,
+		WorkingHours: make([]preferences.DayHours, 0, len(p.WorkingHours)),
+		OutOfOffice:  make([]preferences.OutOfOffice, 0, len(p.OutOfOffice)),
+	}
+	for _, h := range p.WorkingHours {
+		start, err := ParseClock(h.Start)
+		if err != nil {
+			return res, err
+		}
+		end, err := ParseClock(h.End)
+		if err != nil {
+			return res, err
+		}
+		res.WorkingHours = append(res.WorkingHours, preferences.DayHours{
+			Weekday: time.Weekday(h.Weekday),
+			Start:   start,
+			End:     end,
+		})
+	}
+	for _, o := range p.OutOfOffice {
+		res.OutOfOffice = append(res.OutOfOffice, preferences.OutOfOffice(o))
+	}
+	return res, nil
+}
+
+func FromProfile(p preferences.Profile) Preferences {
+	res := Preferences{
+		UserUUID:     p.UserUUID,
+		TimeZone:     p.TimeZone,
+		WorkingHours: make([]DayHours, 0, len(p.WorkingHours)),
+		OutOfOffice:  make([]OutOfOffice, 0, len(p.OutOfOffice)),
+	}
+	for _, h := range p.WorkingHours {
+		res.WorkingHours = append(res.WorkingHours, DayHours{
+			Weekday: int(h.Weekday),
+			Start:   FormatClock(h.Start),
+			End:     FormatClock(h.End),
+		})
+	}
+	for _, o := range p.OutOfOffice {
+		res.OutOfOffice = append(res.OutOfOffice, OutOfOffice(o))
+	}
+	return res
+}
+
+type AddWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Secret string   `json:"secret" validate:"omitempty,min=16"`
+	Types  []string `json:"types" validate:"dive,oneof=created updated deleted"`
+}
+
+type Webhook struct {
+	ID        uint64    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Types     []string  `json:"types"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AddWebhookResponse struct {
+	resp.ValidationResponse
+	Webhook *Webhook `json:"webhook,omitempty"`
+}
+
+type DeleteWebhookRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+type DeleteWebhookResponse struct {
+	resp.ValidationResponse
+	ID uint64 `json:"id"`
+}
+
+type ListWebhooksResponse struct {
+	resp.ValidationResponse
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type DeadLetter struct {
+	DeliveryID     string          `json:"deliveryId"`
+	SubscriptionID uint64          `json:"webhookId"`
+	URL            string          `json:"url"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"lastError"`
+	FailedAt       time.Time       `json:"failedAt"`
+}
+
+type ListDeadLettersResponse struct {
+	resp.ValidationResponse
+	DeadLetters []DeadLetter `json:"deadLetters"`
+}
+
+type CreateAPIKeyRequest struct {
+	UserUUID uint64 `json:"userUUID" validate:"required"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Scope    string `json:"scope" validate:"required,oneof=read_only read_write"`
+}
+
+type RotateAPIKeyRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+type RevokeAPIKeyRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// APIKey ключ без хеша. Key заполняется только в ответах на создание и
+// ротацию, потом открытый текст ключа получить нельзя
+type APIKey struct {
+	ID         uint64     `json:"id"`
+	UserUUID   uint64     `json:"userUUID"`
+	Name       string     `json:"name"`
+	Scope      string     `json:"scope"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RotatedAt  *time.Time `json:"rotatedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+type APIKeyResponse struct {
+	resp.ValidationResponse
+	APIKey *APIKey `json:"apiKey,omitempty"`
+}
+
+type RevokeAPIKeyResponse struct {
+	resp.ValidationResponse
+	ID uint64 `json:"id"`
+}
+
+type ListAPIKeysResponse struct {
+	resp.ValidationResponse
+	APIKeys []APIKey `json:"apiKeys"`
+}
+
+// FromAPIKey plain открытый текст ключа, пустой везде, кроме создания и
+// ротации
+func FromAPIKey(k apikey.Key, plain string) APIKey {
+	return APIKey{
+		ID:         k.ID,
+		UserUUID:   k.UserUUID,
+		Name:       k.Name,
+		Scope:      string(k.Scope),
+		Prefix:     k.Prefix,
+		Key:        plain,
+		CreatedAt:  k.CreatedAt,
+		RotatedAt:  optionalTime(k.RotatedAt),
+		LastUsedAt: optionalTime(k.LastUsedAt),
+		RevokedAt:  optionalTime(k.RevokedAt),
+	}
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// LogValue убирает пароль из логов decodeRequest
+func (r RegisterRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("email", r.Email), slog.String("name", r.Name))
+}
+
+type User struct {
+	UUID      uint64    `json:"UUID"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type RegisterResponse struct {
+	resp.ValidationResponse
+	User *User `json:"user,omitempty"`
+}
+
+func FromUser(u user.User) User {
+	return User{UUID: u.UUID, Email: u.Email, Name: u.Name, CreatedAt: u.CreatedAt}
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LogValue убирает пароль из логов decodeRequest
+func (r LoginRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("email", r.Email))
+}
+
+type LoginResponse struct {
+	resp.ValidationResponse
+	Token     string    `json:"token"`
+	TokenType string    `json:"tokenType"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type LogoutResponse struct {
+	resp.ValidationResponse
+}
+
+// FromSubscription секрет в ответ включается только при withSecret
+func FromSubscription(s webhook.Subscription, withSecret bool) Webhook {
+	res := Webhook{
+		ID:        s.ID,
+		URL:       s.URL,
+		Types:     make([]string, 0, len(s.Types)),
+		CreatedAt: s.CreatedAt,
+	}
+	if withSecret {
+		res.Secret = s.Secret
+	}
+	for _, t := range s.Types {
+		res.Types = append(res.Types, string(t))
+	}
+	return res
+}
+
+func FromDeadLetters(ds []webhook.DeadLetter) []DeadLetter {
+	res := make([]DeadLetter, 0, len(ds))
+	for _, d := range ds {
+		res = append(res, DeadLetter{
+			DeliveryID:     d.DeliveryID,
+			SubscriptionID: d.SubscriptionID,
+			URL:            d.URL,
+			Type:           string(d.Type),
+			Payload:        d.Payload,
+			Attempts:       d.Attempts,
+			LastError:      d.LastError,
+			FailedAt:       d.FailedAt,
+		})
+	}
+	return res
+}
+
+// WSRequest сообщение клиента в WebSocket соединении.
+// Type: subscribe, unsubscribe, create, update, delete
+type WSRequest struct {
+	Type           string          `json:"type"`
+	RequestID      string          `json:"requestId"`
+	SubscriptionID uint64          `json:"subscriptionId"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// WSResponse сообщение сервера в WebSocket соединении.
+// Type: subscribed, unsubscribed, diff, result, error. Code у ошибок
+// принимает те же значения, что code в problem+json ответах
+type WSResponse struct {
+	Type           string            `json:"type"`
+	RequestID      string            `json:"requestId,omitempty"`
+	SubscriptionID uint64            `json:"subscriptionId,omitempty"`
+	Op             string            `json:"op,omitempty"`
+	Status         string            `json:"status,omitempty"`
+	Code           string            `json:"code,omitempty"`
+	Errors         map[string]string `json:"errors,omitempty"`
+	UUID           uint64            `json:"UUID,omitempty"`
+	Event          *event.Event      `json:"event,omitempty"`
+	Events         []event.Event     `json:"events,omitempty"`
+}
+
 func FromEvents(events []event.Event) []UserEvent {
 	res := make([]UserEvent, 0, len(events))
 	for _, ev := range events {
 		res = append(res, UserEvent{
-			Date:  ev.Date,
-			Title: ev.Title,
-			Desc:  ev.Desc,
+			Date:      ev.Date,
+			End:       ev.EndTime(),
+			Title:     ev.Title,
+			Desc:      ev.Desc,
+			Reminders: FromReminders(ev.Reminders),
+		})
+	}
+	return res
+}
+
+func ToReminders(rs []Reminder) []event.Reminder {
+	if len(rs) == 0 {
+		return nil
+	}
+	res := make([]event.Reminder, 0, len(rs))
+	for _, r := range rs {
+		res = append(res, event.Reminder{Before: time.Duration(r.MinutesBefore) * time.Minute})
+	}
+	return res
+}
+
+func FromReminders(rs []event.Reminder) []Reminder {
+	if len(rs) == 0 {
+		return nil
+	}
+	res := make([]Reminder, 0, len(rs))
+	for _, r := range rs {
+		res = append(res, Reminder{MinutesBefore: int(r.Before / time.Minute)})
+	}
+	return res
+}
+
+// ShareCalendarRequest принципал задается либо userUUID, либо everyone для
+// всей компании. Для everyone допустимы только freebusy и viewer
+type ShareCalendarRequest struct {
+	CalendarUUID uint64 `json:"calendarUUID" validate:"required"`
+	UserUUID     uint64 `json:"userUUID" validate:"required_without=Everyone,excluded_with=Everyone"`
+	Everyone     bool   `json:"everyone"`
+	Role         string `json:"role" validate:"required,oneof=freebusy viewer editor owner"`
+}
+
+func (r ShareCalendarRequest) ToGrant() acl.Grant {
+	role, _ := acl.ParseRole(r.Role)
+	return acl.Grant{CalendarUUID: r.CalendarUUID, UserUUID: principal(r.UserUUID, r.Everyone), Role: role}
+}
+
+type UnshareCalendarRequest struct {
+	CalendarUUID uint64 `json:"calendarUUID" validate:"required"`
+	UserUUID     uint64 `json:"userUUID" validate:"required_without=Everyone,excluded_with=Everyone"`
+	Everyone     bool   `json:"everyone"`
+}
+
+func (r UnshareCalendarRequest) Principal() uint64 {
+	return principal(r.UserUUID, r.Everyone)
+}
+
+func principal(user uint64, everyone bool) uint64 {
+	if everyone {
+		return acl.Everyone
+	}
+	return user
+}
+
+type CalendarGrant struct {
+	CalendarUUID uint64 `json:"calendarUUID"`
+	UserUUID     uint64 `json:"userUUID,omitempty"`
+	Everyone     bool   `json:"everyone,omitempty"`
+	Role         string `json:"role"`
+}
+
+func FromGrant(g acl.Grant) CalendarGrant {
+	return CalendarGrant{
+		CalendarUUID: g.CalendarUUID,
+		UserUUID:     g.UserUUID,
+		Everyone:     g.UserUUID == acl.Everyone,
+		Role:         g.Role.String(),
+	}
+}
+
+type ShareCalendarResponse struct {
+	resp.ValidationResponse
+	Grant *CalendarGrant `json:"grant,omitempty"`
+}
+
+type UnshareCalendarResponse struct {
+	resp.ValidationResponse
+	CalendarUUID uint64 `json:"calendarUUID"`
+}
+
+type CalendarACLResponse struct {
+	resp.ValidationResponse
+	Grants []CalendarGrant `json:"grants"`
+}
+
+// CreateShareLinkRequest from и to ограничивают видимые по ссылке события,
+// без expiresAt ссылка бессрочная
+type CreateShareLinkRequest struct {
+	CalendarUUID uint64     `json:"calendarUUID" validate:"required"`
+	From         *time.Time `json:"from"`
+	To           *time.Time `json:"to"`
+	ExpiresAt    *time.Time `json:"expiresAt" validate:"omitempty,future"`
+}
+
+func (r CreateShareLinkRequest) ToLink() sharelink.Link {
+	return sharelink.Link{
+		CalendarUUID: r.CalendarUUID,
+		From:         valueTime(r.From),
+		To:           valueTime(r.To),
+		ExpiresAt:    valueTime(r.ExpiresAt),
+	}
+}
+
+func valueTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+type RevokeShareLinkRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// ShareLink ссылка без хеша токена. Token и URL заполняются только в ответе
+// на создание
+type ShareLink struct {
+	ID           uint64     `json:"id"`
+	CalendarUUID uint64     `json:"calendarUUID"`
+	From         *time.Time `json:"from,omitempty"`
+	To           *time.Time `json:"to,omitempty"`
+	Token        string     `json:"token,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	CreatedBy    uint64     `json:"createdBy,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+}
+
+type ShareLinkResponse struct {
+	resp.ValidationResponse
+	ShareLink *ShareLink `json:"shareLink,omitempty"`
+}
+
+type RevokeShareLinkResponse struct {
+	resp.ValidationResponse
+	ID uint64 `json:"id"`
+}
+
+type ListShareLinksResponse struct {
+	resp.ValidationResponse
+	ShareLinks []ShareLink `json:"shareLinks"`
+}
+
+// FromShareLink url путь публичной ленты с токеном, пустой везде, кроме создания
+func FromShareLink(l sharelink.Link, token, url string) ShareLink {
+	return ShareLink{
+		ID:           l.ID,
+		CalendarUUID: l.CalendarUUID,
+		From:         optionalTime(l.From),
+		To:           optionalTime(l.To),
+		Token:        token,
+		URL:          url,
+		CreatedBy:    l.CreatedBy,
+		CreatedAt:    l.CreatedAt,
+		ExpiresAt:    optionalTime(l.ExpiresAt),
+		RevokedAt:    optionalTime(l.RevokedAt),
+	}
+}
+
+// ExportTenantResponse данные организации вызывающего. Секреты вебхуков,
+// токены ссылок, хеши паролей и ключей не выгружаются
+type ExportTenantResponse struct {
+	resp.ValidationResponse
+	OrgUUID    uint64          `json:"orgUUID"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Events     []event.Event   `json:"events"`
+	Grants     []CalendarGrant `json:"grants"`
+	Webhooks   []Webhook       `json:"webhooks"`
+	ShareLinks []ShareLink     `json:"shareLinks"`
+	Profiles   []Preferences   `json:"profiles"`
+	Users      []User          `json:"users"`
+	APIKeys    []APIKey        `json:"apiKeys"`
+}
+
+func FromSnapshot(s tenantadmin.Snapshot) ExportTenantResponse {
+	res := ExportTenantResponse{
+		ValidationResponse: resp.OK(),
+		OrgUUID:            s.OrgUUID,
+		ExportedAt:         s.ExportedAt,
+		Events:             s.Events,
+		Grants:             make([]CalendarGrant, 0, len(s.Grants)),
+		Webhooks:           make([]Webhook, 0, len(s.Webhooks)),
+		ShareLinks:         make([]ShareLink, 0, len(s.ShareLinks)),
+		Profiles:           make([]Preferences, 0, len(s.Profiles)),
+		Users:              make([]User, 0, len(s.Users)),
+		APIKeys:            make([]APIKey, 0, len(s.APIKeys)),
+	}
+	if res.Events == nil {
+		res.Events = []event.Event{}
+	}
+	for _, g := range s.Grants {
+		res.Grants = append(res.Grants, FromGrant(g))
+	}
+	for _, w := range s.Webhooks {
+		res.Webhooks = append(res.Webhooks, FromSubscription(w, false))
+	}
+	for _, l := range s.ShareLinks {
+		res.ShareLinks = append(res.ShareLinks, FromShareLink(l, "", ""))
+	}
+	for _, p := range s.Profiles {
+		res.Profiles = append(res.Profiles, FromProfile(p))
+	}
+	for _, u := range s.Users {
+		res.Users = append(res.Users, FromUser(u))
+	}
+	for _, k := range s.APIKeys {
+		res.APIKeys = append(res.APIKeys, FromAPIKey(k, ""))
+	}
+	return res
+}
+
+// DeleteTenantRequest orgUUID подтверждает удаление и должен совпадать
+// с организацией вызывающего. Указатель, чтобы отличить организацию 0
+// от пропущенного поля
+type DeleteTenantRequest struct {
+	OrgUUID *uint64 `json:"orgUUID" validate:"required"`
+}
+
+type DeleteTenantResponse struct {
+	resp.ValidationResponse
+	OrgUUID    uint64 `json:"orgUUID"`
+	Events     int    `json:"events"`
+	Grants     int    `json:"grants"`
+	Webhooks   int    `json:"webhooks"`
+	ShareLinks int    `json:"shareLinks"`
+	Profiles   int    `json:"profiles"`
+	Users      int    `json:"users"`
+	Sessions   int    `json:"sessions"`
+	APIKeys    int    `json:"apiKeys"`
+}
+
+func FromDeleted(orgUUID uint64, d tenantadmin.Deleted) DeleteTenantResponse {
+	return DeleteTenantResponse{
+		ValidationResponse: resp.OK(),
+		OrgUUID:            orgUUID,
+		Events:             d.Events,
+		Grants:             d.Grants,
+		Webhooks:           d.Webhooks,
+		ShareLinks:         d.ShareLinks,
+		Profiles:           d.Profiles,
+		Users:              d.Users,
+		Sessions:           d.Sessions,
+		APIKeys:            d.APIKeys,
+	}
+}
+
+// HealthResponse отчет /healthz и /readyz. Без ValidationResponse: формат
+// читают оркестраторы, а не клиенты API
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
+
+type HealthCheck struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+func FromReport(r health.Report) HealthResponse {
+	res := HealthResponse{Status: string(r.Status), Checks: make([]HealthCheck, 0, len(r.Checks))}
+	for _, c := range r.Checks {
+		res.Checks = append(res.Checks, HealthCheck{
+			Name:       c.Name,
+			Status:     string(c.Status),
+			Error:      c.Error,
+			DurationMs: float64(c.Duration.Microseconds()) / 1000,
 		})
 	}
 	return res
@@ -0,0 +1,29 @@
+package handlerdto
+
+import (
+	"calendar/internal/scheduling"
+	valResp "calendar/pkg/validator"
+	"testing"
+	"time"
+)
+
+// TestSuggestSlotsRangeMatchesScheduler тег maxduration у To должен
+// пропускать ровно scheduling.MaxRange, иначе часть диапазона сервиса
+// недостижима по HTTP или сервис отвечает ошибкой вместо валидации
+func TestSuggestSlotsRangeMatchesScheduler(t *testing.T) {
+	from := time.Now().Add(time.Hour)
+	req := SuggestSlotsRequest{
+		Participants:    []uint64{1},
+		DurationMinutes: 30,
+		From:            from,
+		To:              from.Add(scheduling.MaxRange),
+	}
+	if err := valResp.Struct(req); err != nil {
+		t.Fatalf("range of scheduling.MaxRange rejected: %v", err)
+	}
+
+	req.To = from.Add(scheduling.MaxRange + time.Minute)
+	if err := valResp.Struct(req); err == nil {
+		t.Fatal("range longer than scheduling.MaxRange accepted")
+	}
+}
@@ -5,7 +5,7 @@ import (
 	dto "calendar/internal/infrastructure/http/handlers/dto"
 	"calendar/internal/infrastructure/http/middleware"
 	"calendar/internal/infrastructure/http/response"
-	inmem "calendar/internal/infrastructure/storage/in_memory"
+	"calendar/internal/preferences"
 	"calendar/pkg/sl_logger/sl"
 	valResp "calendar/pkg/validator"
 
@@ -20,15 +20,15 @@ var (
 	errInvalidDateFormat = errors.New("invalid date format")
 )
 
-func NewEventsForDayHandler(log *slog.Logger, svc event.Service) http.HandlerFunc {
+func NewEventsForDayHandler(log *slog.Logger, svc event.Service, prefs preferences.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			response.MethodNotAllowed(w, r, http.MethodGet)
 			return
 		}
 
 		const op = "handlers.event.getforday"
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetRequestID(r)),
 		)
@@ -38,7 +38,7 @@ func NewEventsForDayHandler(log *slog.Logger, svc event.Service) http.HandlerFun
 			log.Error("bad request",
 				slog.String("type", errMissingDateParam.Error()),
 			)
-			getEventForDayResponseErr(w, errMissingDateParam.Error())
+			response.BadRequest(w, r, errMissingDateParam)
 			return
 		}
 		date, err := time.Parse("2006-01-02", dateS)
@@ -47,36 +47,38 @@ func NewEventsForDayHandler(log *slog.Logger, svc event.Service) http.HandlerFun
 				slog.String("type", errInvalidDateFormat.Error()),
 				sl.Err(err),
 			)
-			getEventForDayResponseErr(w, errInvalidDateFormat.Error())
+			response.BadRequest(w, r, errInvalidDateFormat)
 			return
 		}
 
-		events, err := svc.ListByDay(date)
+		events, err := svc.ListByDay(r.Context(), date)
 		if err != nil {
 			switch {
-			case errors.Is(err, inmem.ErrNoValue):
+			case errors.Is(err, event.ErrNotFound):
 				log.Error("failed to get event", sl.Err(err))
 			default:
-				log.Error("unexpected error adding event", sl.Err(err))
+				log.Error("unexpected error listing events", sl.Err(err))
+				response.Error(w, r, err)
+				return
+			}
+		}
+
+		res := dto.FromEvents(events)
+		if wantsWorkingHoursAnnotation(r) {
+			if err := annotateWorkingHours(r.Context(), prefs, events, res); err != nil {
+				log.Error("failed to annotate working hours", sl.Err(err))
 			}
 		}
 
 		log.Info("events getted")
-		getEventForDayResponseOK(w, events)
+		getEventForDayResponseOK(w, res)
 	}
 }
 
-func getEventForDayResponseOK(w http.ResponseWriter, e []event.Event) {
+func getEventForDayResponseOK(w http.ResponseWriter, e []dto.UserEvent) {
 	r := dto.GetEventResponse{
 		ValidationResponse: valResp.OK(),
-		Events:             dto.FromEvents(e),
+		Events:             e,
 	}
 	response.WriteJSON(w, http.StatusOK, r)
 }
-
-func getEventForDayResponseErr(w http.ResponseWriter, e string) {
-	r := dto.GetEventResponse{
-		ValidationResponse: valResp.Error(e),
-	}
-	response.WriteJSON(w, http.StatusBadRequest, r)
-}
@@ -2,11 +2,12 @@ package handlers
 
 import (
 	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
 	"calendar/internal/infrastructure/http/middleware"
-	inmem "calendar/internal/infrastructure/storage/in_memory"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/preferences"
 	"calendar/pkg/sl_logger/sl"
 
-
 	"errors"
 	"log/slog"
 	"net/http"
@@ -15,15 +16,15 @@ import (
 
 
 
-func NewEventsForMonthHandler(log *slog.Logger, svc event.Service) http.HandlerFunc {
+func NewEventsForMonthHandler(log *slog.Logger, svc event.Service, prefs preferences.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			response.MethodNotAllowed(w, r, http.MethodGet)
 			return
 		}
 
 		const op = "handlers.event.getformonth"
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetRequestID(r)),
 		)
@@ -33,7 +34,7 @@ func NewEventsForMonthHandler(log *slog.Logger, svc event.Service) http.HandlerF
 			log.Error("bad request",
 				slog.String("type", errMissingDateParam.Error()),
 			)
-			getEventForDayResponseErr(w, errMissingDateParam.Error())
+			response.BadRequest(w, r, errMissingDateParam)
 			return
 		}
 		date, err := time.Parse("2006-01-02", dateS)
@@ -42,22 +43,31 @@ func NewEventsForMonthHandler(log *slog.Logger, svc event.Service) http.HandlerF
 				slog.String("type", errInvalidDateFormat.Error()),
 				sl.Err(err),
 			)
-			getEventForDayResponseErr(w, errInvalidDateFormat.Error())
+			response.BadRequest(w, r, errInvalidDateFormat)
 			return
 		}
 
-		events, err := svc.ListByMonth(date)
+		events, err := svc.ListByMonth(r.Context(), date)
 		if err != nil {
 			switch {
-			case errors.Is(err, inmem.ErrNoValue):
+			case errors.Is(err, event.ErrNotFound):
 				log.Error("failed to get event", sl.Err(err))
 			default:
-				log.Error("unexpected error adding event", sl.Err(err))
+				log.Error("unexpected error listing events", sl.Err(err))
+				response.Error(w, r, err)
+				return
+			}
+		}
+
+		res := dto.FromEvents(events)
+		if wantsWorkingHoursAnnotation(r) {
+			if err := annotateWorkingHours(r.Context(), prefs, events, res); err != nil {
+				log.Error("failed to annotate working hours", sl.Err(err))
 			}
 		}
 
 		log.Info("events getted")
-		getEventForDayResponseOK(w, events)
+		getEventForDayResponseOK(w, res)
 	}
 }
 
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"calendar/internal/changefeed"
+	"calendar/internal/event"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	errInvalidCalendarParam = errors.New("invalid calendar parameter")
+	errInvalidLastEventID   = errors.New("invalid Last-Event-ID")
+)
+
+const defaultHeartbeat = 15 * time.Second
+
+type streamFilter struct {
+	user     uint64
+	calendar uint64
+	// visible проверка доступа к календарю события
+	visible func(event.Event) bool
+}
+
+func (f streamFilter) match(e changefeed.Entry) bool {
+	if f.user != 0 && e.Change.Event.UserUUID != f.user {
+		return false
+	}
+	if f.calendar != 0 && e.Change.Event.CalendarUUID != f.calendar {
+		return false
+	}
+	return f.visible == nil || f.visible(e.Change.Event)
+}
+
+// NewEventsStreamHandler создает обработчик GET /events/stream, отдающий
+// изменения событий через Server-Sent Events.
+// Фильтры: user, calendar. Возобновление по заголовку Last-Event-ID
+// (или параметру lastEventId для клиентов без поддержки заголовка).
+// Если часть изменений уже вытеснена из журнала, первым приходит событие reset.
+// Изменения календарей, которые пользователь не может просматривать, пропускаются
+func NewEventsStreamHandler(log *slog.Logger, svc event.Service, feed *changefeed.Log, heartbeat time.Duration) http.HandlerFunc {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.event.stream"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		filter, lastID, err := parseStreamParams(r)
+		if err != nil {
+			log.Error("bad request", sl.Err(err))
+			response.BadRequest(w, r, err)
+			return
+		}
+		filter.visible = func(e event.Event) bool { return svc.CanView(r.Context(), e) }
+
+		rc := http.NewResponseController(w)
+		// поток живет дольше WriteTimeout сервера
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Error("streaming unsupported", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		backlog, sub, complete := feed.Subscribe(lastID)
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		log.Info("stream opened", slog.Uint64("last_event_id", lastID), slog.Bool("complete", complete))
+
+		if !complete {
+			fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+		}
+		for _, e := range backlog {
+			if filter.match(e) {
+				if err := writeSSE(w, e); err != nil {
+					return
+				}
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				log.Info("stream closed by client")
+				return
+			case e, ok := <-sub.C():
+				if !ok {
+					if errors.Is(sub.Err(), changefeed.ErrClosed) {
+						log.Info("stream closed on server shutdown")
+					} else {
+						log.Warn("stream subscriber lagged behind, closing")
+					}
+					return
+				}
+				if !filter.match(e) {
+					continue
+				}
+				if err := writeSSE(w, e); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func parseStreamParams(r *http.Request) (streamFilter, uint64, error) {
+	var f streamFilter
+	var err error
+	q := r.URL.Query()
+
+	if s := q.Get("user"); s != "" {
+		if f.user, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return f, 0, errInvalidUserParam
+		}
+	}
+	if s := q.Get("calendar"); s != "" {
+		if f.calendar, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return f, 0, errInvalidCalendarParam
+		}
+	}
+
+	var lastID uint64
+	s := r.Header.Get("Last-Event-ID")
+	if s == "" {
+		s = q.Get("lastEventId")
+	}
+	if s != "" {
+		if lastID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return f, 0, errInvalidLastEventID
+		}
+	}
+	return f, lastID, nil
+}
+
+func writeSSE(w io.Writer, e changefeed.Entry) error {
+	data, err := json.Marshal(e.Change)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Change.Type, data)
+	return err
+}
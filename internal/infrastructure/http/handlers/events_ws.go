@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/changefeed"
+	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/request"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait    = 10 * time.Second
+	wsPongWait     = 60 * time.Second
+	wsPingPeriod   = wsPongWait * 9 / 10
+	wsMaxMessage   = 64 << 10
+	wsSendBuffer   = 64
+	wsCloseLagging = "client is too slow"
+	wsCloseServer  = "server is shutting down"
+)
+
+var (
+	errUnknownMessageType = errors.New("unknown message type")
+	errInvalidRange       = errors.New("range end must be after start")
+	errUnknownSubscribe   = errors.New("unknown subscription")
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+// NewEventsWSHandler создает обработчик GET /events/ws.
+// Клиент подписывается на диапазоны дат (subscribe/unsubscribe) и получает
+// снимок событий и дальнейшие изменения (diff), а также может выполнять
+// create/update/delete с той же валидацией, что и HTTP обработчики.
+// Каждое сообщение клиента может нести requestId, он возвращается в ответе;
+// если его нет, сервер генерирует его так же, как middleware.RequestID
+func NewEventsWSHandler(log *slog.Logger, svc event.Service, feed *changefeed.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.event.ws"
+		// X-Request-ID рукопожатия идентифицирует соединение,
+		// request_id в логах у каждой команды свой
+		log := log.With(
+			slog.String("op", op),
+			slog.String("conn_id", middleware.GetRequestID(r)),
+		)
+
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade уже ответил клиенту ошибкой
+			log.Error("failed to upgrade connection", sl.Err(err))
+			return
+		}
+
+		c := &wsConn{
+			log:  log,
+			svc:  svc,
+			ctx:  r.Context(),
+			conn: conn,
+			lang: valResp.Negotiate(r.Header.Get("Accept-Language")),
+			send: make(chan dto.WSResponse, wsSendBuffer),
+			done: make(chan struct{}),
+			subs: make(map[uint64]*wsRange),
+		}
+		if id, ok := middleware.GetIdentity(r); ok {
+			c.identity = &id
+		}
+		_, c.feed, _ = feed.Subscribe(0)
+
+		log.Info("websocket connected")
+		c.run()
+		log.Info("websocket disconnected")
+	}
+}
+
+type wsRange struct {
+	from  time.Time
+	to    time.Time
+	known map[uint64]bool
+}
+
+func (rg *wsRange) contains(e event.Event) bool {
+	return e.Date.Before(rg.to) && e.EndTime().After(rg.from)
+}
+
+type wsConn struct {
+	log *slog.Logger
+	svc event.Service
+	// ctx контекст handshake, несет identity для проверок доступа сервиса
+	ctx  context.Context
+	conn *websocket.Conn
+	// lang язык ошибок валидации, выбирается один раз при handshake
+	lang valResp.Lang
+	// identity пользователь handshake, nil без аутентификации
+	identity *auth.Identity
+	feed     *changefeed.Subscription
+	send     chan dto.WSResponse
+	done     chan struct{}
+	once     sync.Once
+
+	mu      sync.Mutex
+	subs    map[uint64]*wsRange
+	lastSub uint64
+}
+
+func (c *wsConn) run() {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.writeLoop()
+	}()
+	go func() {
+		defer wg.Done()
+		c.feedLoop()
+	}()
+
+	c.readLoop()
+	c.close()
+	wg.Wait()
+	c.feed.Close()
+}
+
+func (c *wsConn) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+func (c *wsConn) readLoop() {
+	c.conn.SetReadLimit(wsMaxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg dto.WSRequest
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				c.reply(wsFromError(request.ErrFailedToDecodeReqBody))
+				continue
+			}
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				c.log.Error("websocket read failed", sl.Err(err))
+			}
+			return
+		}
+		if msg.RequestID == "" {
+			msg.RequestID = uuid.New().String()
+		}
+		c.handle(msg)
+	}
+}
+
+func (c *wsConn) handle(msg dto.WSRequest) {
+	log := c.log.With(
+		slog.String("request_id", msg.RequestID),
+		slog.String("type", msg.Type),
+	)
+
+	var res dto.WSResponse
+	switch msg.Type {
+	case "subscribe":
+		res = c.subscribe(msg)
+		if res.Status == valResp.StatusOK {
+			log.Info("websocket subscribed", slog.Uint64("subscription", res.SubscriptionID))
+			return
+		}
+	case "unsubscribe":
+		res = c.unsubscribe(msg)
+	case "create":
+		var req dto.AddEventRequest
+		res = c.command(msg, &req, func() (uint64, error) {
+			e := req.ToEvent()
+			ownByIdentity(c.identity, &e)
+			return c.svc.Add(c.ctx, e)
+		})
+	case "update":
+		var req dto.UpdateEventRequest
+		res = c.command(msg, &req, func() (uint64, error) {
+			return req.UUID, c.svc.Update(c.ctx, req.ToEvent())
+		})
+	case "delete":
+		var req dto.DeleteEventRequest
+		res = c.command(msg, &req, func() (uint64, error) {
+			return req.UUID, c.svc.Delete(c.ctx, req.UUID)
+		})
+	default:
+		res = wsError(response.CodeInvalidArgument, errUnknownMessageType.Error())
+	}
+
+	res.RequestID = msg.RequestID
+	if res.Status == valResp.StatusError {
+		log.Error("websocket command failed", slog.Any("errors", res.Errors))
+	} else {
+		log.Info("websocket command handled")
+	}
+	c.reply(res)
+}
+
+// command декодирует payload в req, валидирует его и выполняет действие
+func (c *wsConn) command(msg dto.WSRequest, req any, do func() (uint64, error)) dto.WSResponse {
+	// все команды меняют события, ключу только на чтение они недоступны
+	if c.identity != nil && !c.identity.Has(auth.ScopeWrite) {
+		return wsFromError(auth.ErrInsufficientScope)
+	}
+	if len(msg.Payload) == 0 {
+		return wsFromError(request.ErrEmptyReqBody)
+	}
+	if err := json.Unmarshal(msg.Payload, req); err != nil {
+		return wsFromError(request.ErrFailedToDecodeReqBody)
+	}
+	if errResp, err := validateRequest(req, c.lang); err != nil {
+		return dto.WSResponse{Type: "error", Status: errResp.Status, Code: string(response.CodeValidationFailed), Errors: errResp.Errors}
+	}
+
+	id, err := do()
+	if err != nil {
+		c.log.Error("websocket command failed", slog.String("type", msg.Type), sl.Err(err))
+		return wsFromError(err)
+	}
+	return dto.WSResponse{Type: "result", Status: valResp.StatusOK, UUID: id}
+}
+
+func (c *wsConn) subscribe(msg dto.WSRequest) dto.WSResponse {
+	if !msg.To.After(msg.From) {
+		return wsError(response.CodeInvalidArgument, errInvalidRange.Error())
+	}
+
+	// снимок, регистрация и отправка ответа под одной блокировкой, чтобы
+	// feedLoop не разослал изменения раньше, чем клиент получит снимок
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events, err := c.svc.ListByRange(c.ctx, msg.From, msg.To)
+	if err != nil {
+		c.log.Error("failed to list events", sl.Err(err))
+		return wsFromError(err)
+	}
+
+	rg := &wsRange{from: msg.From, to: msg.To, known: make(map[uint64]bool, len(events))}
+	for _, e := range events {
+		rg.known[e.UUID] = true
+	}
+	c.lastSub++
+	c.subs[c.lastSub] = rg
+
+	res := dto.WSResponse{
+		Type:           "subscribed",
+		RequestID:      msg.RequestID,
+		Status:         valResp.StatusOK,
+		SubscriptionID: c.lastSub,
+		Events:         events,
+	}
+	c.reply(res)
+	return res
+}
+
+func (c *wsConn) unsubscribe(msg dto.WSRequest) dto.WSResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subs[msg.SubscriptionID]; !ok {
+		return wsError(response.CodeNotFound, errUnknownSubscribe.Error())
+	}
+	delete(c.subs, msg.SubscriptionID)
+	return dto.WSResponse{Type: "unsubscribed", Status: valResp.StatusOK, SubscriptionID: msg.SubscriptionID}
+}
+
+// feedLoop превращает изменения из журнала в diff для каждой подписки клиента.
+// Событие, ушедшее из диапазона или ставшее недоступным после обновления,
+// приходит как deleted
+func (c *wsConn) feedLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case entry, ok := <-c.feed.C():
+			if !ok {
+				msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, wsCloseLagging)
+				if errors.Is(c.feed.Err(), changefeed.ErrClosed) {
+					msg = websocket.FormatCloseMessage(websocket.CloseGoingAway, wsCloseServer)
+				}
+				c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait))
+				c.close()
+				return
+			}
+			visible := c.svc.CanView(c.ctx, entry.Change.Event)
+			c.mu.Lock()
+			for id, rg := range c.subs {
+				if op, ok := diffOp(rg, entry.Change, visible); ok {
+					e := entry.Change.Event
+					c.reply(dto.WSResponse{Type: "diff", SubscriptionID: id, Op: op, Event: &e})
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+func diffOp(rg *wsRange, ch event.Change, visible bool) (string, bool) {
+	id := ch.Event.UUID
+	known := rg.known[id]
+
+	if ch.Type == event.ChangeDeleted || !visible || !rg.contains(ch.Event) {
+		if !known {
+			return "", false
+		}
+		delete(rg.known, id)
+		return string(event.ChangeDeleted), true
+	}
+
+	rg.known[id] = true
+	if known {
+		return string(event.ChangeUpdated), true
+	}
+	return string(event.ChangeCreated), true
+}
+
+// writeLoop единственный писатель в соединение, при завершении закрывает его,
+// что прерывает блокирующее чтение в readLoop
+func (c *wsConn) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+	defer c.conn.Close()
+
+	for {
+		select {
+		case <-c.done:
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(wsWriteWait))
+			return
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.log.Error("websocket write failed", sl.Err(err))
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// reply не блокирует: клиент, не успевающий читать, отключается
+func (c *wsConn) reply(msg dto.WSResponse) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	default:
+		c.log.Warn("websocket send buffer is full, closing")
+		c.close()
+	}
+}
+
+func wsError(code response.Code, msg string) dto.WSResponse {
+	r := valResp.Error(msg)
+	return dto.WSResponse{Type: "error", Status: r.Status, Code: string(code), Errors: r.Errors}
+}
+
+// wsFromError ошибка сервиса с кодом и текстом из той же таблицы, что и в
+// HTTP ответах: текст хранилища и внутренних ошибок клиенту не уходит
+func wsFromError(err error) dto.WSResponse {
+	p := response.FromError(err)
+	return wsError(p.Code, p.Detail)
+}
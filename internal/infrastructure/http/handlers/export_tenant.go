@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/tenantadmin"
+	"calendar/pkg/sl_logger/sl"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewExportTenantHandler создает обработчик GET /admin/export_tenant.
+// Выгружает все данные организации вызывающего без проверки ролей календарей
+func NewExportTenantHandler(log *slog.Logger, svc tenantadmin.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.tenant.export"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		snap, err := svc.Export(r.Context())
+		if err != nil {
+			log.Error("failed to export tenant", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("tenant exported",
+			slog.Uint64("org", snap.OrgUUID),
+			slog.Int("events", len(snap.Events)),
+		)
+		response.WriteJSON(w, http.StatusOK, dto.FromSnapshot(snap))
+	}
+}
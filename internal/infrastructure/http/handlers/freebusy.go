@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/ical"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NewFreeBusyHandler создает обработчик POST /freebusy, возвращающий занятые
+// интервалы пользователей без деталей событий.
+// Формат ответа JSON, либо VFREEBUSY если клиент запросил text/calendar
+// через заголовок Accept или параметр format=ics
+func NewFreeBusyHandler(log *slog.Logger, svc event.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.event.freebusy"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.FreeBusyRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		busy, err := svc.FreeBusy(r.Context(), req.Users, req.From, req.To)
+		if err != nil {
+			log.Error("unexpected error computing free/busy", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("free/busy computed", slog.Int("users", len(busy)))
+
+		if wantsICal(r) {
+			freeBusyResponseICal(w, req.From, req.To, busy)
+			return
+		}
+		freeBusyResponseOK(w, req.From, req.To, busy)
+	}
+}
+
+func wantsICal(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "ics" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/calendar")
+}
+
+func freeBusyResponseOK(w http.ResponseWriter, from, to time.Time, busy []event.FreeBusy) {
+	r := dto.FreeBusyResponse{
+		ValidationResponse: valResp.OK(),
+		From:               from,
+		To:                 to,
+		Users:              busy,
+	}
+	response.WriteJSON(w, http.StatusOK, r)
+}
+
+func freeBusyResponseICal(w http.ResponseWriter, from, to time.Time, busy []event.FreeBusy) {
+	cal := ical.Calendar{FreeBusy: make([]ical.FreeBusy, 0, len(busy))}
+	for _, fb := range busy {
+		periods := make([]ical.Period, 0, len(fb.Busy))
+		for _, iv := range fb.Busy {
+			periods = append(periods, ical.Period{Start: iv.Start, End: iv.End})
+		}
+		cal.FreeBusy = append(cal.FreeBusy, ical.FreeBusy{
+			UID:      fmt.Sprintf("freebusy-%d-%d@calendar", fb.UserUUID, from.Unix()),
+			Attendee: fmt.Sprintf("urn:calendar:user:%d", fb.UserUUID),
+			Start:    from,
+			End:      to,
+			Busy:     periods,
+		})
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.WriteHeader(http.StatusOK)
+	cal.WriteTo(w)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/preferences"
+	"calendar/pkg/sl_logger/sl"
+
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+var (
+	errMissingUserParam = errors.New("missing user parameter")
+	errInvalidUserParam = errors.New("invalid user parameter")
+)
+
+// NewGetPreferencesHandler создает обработчик GET /preferences?user=<UUID>.
+// С аутентификацией user можно опустить, чужой профиль дает 403
+func NewGetPreferencesHandler(log *slog.Logger, svc preferences.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.preferences.get"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		var user uint64
+		if userS := r.URL.Query().Get("user"); userS != "" {
+			var err error
+			if user, err = strconv.ParseUint(userS, 10, 64); err != nil {
+				log.Error("bad request",
+					slog.String("type", errInvalidUserParam.Error()),
+					sl.Err(err),
+				)
+				response.BadRequest(w, r, errInvalidUserParam)
+				return
+			}
+		}
+		user, ok := profileOwner(log, w, r, user)
+		if !ok {
+			return
+		}
+
+		profile, err := svc.Get(r.Context(), user)
+		if err != nil {
+			log.Error("failed to get preferences", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("preferences getted")
+		preferencesResponseOK(w, profile)
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"calendar/internal/health"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/response"
+
+	"net/http"
+)
+
+// NewHealthzHandler создает обработчик GET /healthz. Процесс жив, пока
+// отвечает, в том числе во время остановки
+func NewHealthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		response.WriteJSON(w, http.StatusOK, dto.HealthResponse{Status: string(health.StatusOK)})
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"calendar/internal/apikey"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// NewListAPIKeysHandler создает обработчик GET /admin/api_keys[?user=<UUID>].
+// Без user возвращаются ключи всех пользователей, открытый текст и хеши
+// ключей не возвращаются
+func NewListAPIKeysHandler(log *slog.Logger, svc apikey.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.api_key.list"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		var user uint64
+		if userS := r.URL.Query().Get("user"); userS != "" {
+			var err error
+			user, err = strconv.ParseUint(userS, 10, 64)
+			if err != nil {
+				log.Error("bad request", slog.String("type", errInvalidUserParam.Error()), sl.Err(err))
+				response.BadRequest(w, r, errInvalidUserParam)
+				return
+			}
+		}
+
+		keys, err := svc.List(r.Context(), user)
+		if err != nil {
+			log.Error("failed to list api keys", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		res := dto.ListAPIKeysResponse{
+			ValidationResponse: valResp.OK(),
+			APIKeys:            make([]dto.APIKey, 0, len(keys)),
+		}
+		for _, k := range keys {
+			res.APIKeys = append(res.APIKeys, dto.FromAPIKey(k, ""))
+		}
+
+		log.Info("api keys listed", slog.Int("count", len(res.APIKeys)))
+		response.WriteJSON(w, http.StatusOK, res)
+	}
+}
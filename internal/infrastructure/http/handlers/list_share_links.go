@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/sharelink"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// NewListShareLinksHandler создает обработчик GET /share_links?calendar=<UUID>.
+// Токены не возвращаются, отозванные ссылки остаются в списке с revokedAt
+func NewListShareLinksHandler(log *slog.Logger, svc sharelink.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.share_link.list"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		calendar, err := strconv.ParseUint(r.URL.Query().Get("calendar"), 10, 64)
+		if err != nil || calendar == 0 {
+			log.Error("bad request", slog.String("type", errInvalidCalendarParam.Error()))
+			response.BadRequest(w, r, errInvalidCalendarParam)
+			return
+		}
+
+		links, err := svc.List(r.Context(), calendar)
+		if err != nil {
+			log.Error("failed to list share links", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		res := dto.ListShareLinksResponse{
+			ValidationResponse: valResp.OK(),
+			ShareLinks:         make([]dto.ShareLink, 0, len(links)),
+		}
+		for _, l := range links {
+			res.ShareLinks = append(res.ShareLinks, dto.FromShareLink(l, "", ""))
+		}
+
+		log.Info("share links listed", slog.Int("count", len(res.ShareLinks)))
+		response.WriteJSON(w, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/webhook"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewListWebhooksHandler создает обработчик GET /webhooks, секреты не возвращаются
+func NewListWebhooksHandler(log *slog.Logger, svc webhook.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.webhook.list"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		subs, err := svc.List(r.Context())
+		if err != nil {
+			log.Error("failed to list webhooks", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		res := dto.ListWebhooksResponse{
+			ValidationResponse: valResp.OK(),
+			Webhooks:           make([]dto.Webhook, 0, len(subs)),
+		}
+		for _, s := range subs {
+			res.Webhooks = append(res.Webhooks, dto.FromSubscription(s, false))
+		}
+
+		log.Info("webhooks getted")
+		response.WriteJSON(w, http.StatusOK, res)
+	}
+}
+
+// NewListDeadLettersHandler создает обработчик GET /webhook_dead_letters
+func NewListDeadLettersHandler(log *slog.Logger, svc webhook.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.webhook.dead_letters"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		ds, err := svc.DeadLetters(r.Context())
+		if err != nil {
+			log.Error("failed to list dead letters", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("dead letters getted")
+		response.WriteJSON(w, http.StatusOK, dto.ListDeadLettersResponse{
+			ValidationResponse: valResp.OK(),
+			DeadLetters:        dto.FromDeadLetters(ds),
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/user"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewLoginHandler создает обработчик POST /login. Открывает сеанс и
+// возвращает его bearer токен, неверные email и пароль неразличимы
+func NewLoginHandler(log *slog.Logger, svc user.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.user.login"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.LoginRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		sess, token, err := svc.Login(req.Email, req.Password)
+		if err != nil {
+			log.Error("failed to login", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("user logged in", slog.Uint64("uuid", sess.UserUUID))
+
+		response.WriteJSON(w, http.StatusOK, dto.LoginResponse{
+			ValidationResponse: valResp.OK(),
+			Token:              token,
+			TokenType:          middleware.SchemeBearer,
+			ExpiresAt:          sess.ExpiresAt,
+		})
+	}
+}
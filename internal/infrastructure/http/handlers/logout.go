@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/user"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+var errNoSession = errors.New("request is not authenticated with a session token")
+
+// NewLogoutHandler создает обработчик POST /logout. Отзывает сеанс токена
+// запроса, API ключи и токены внешнего провайдера сеанса не имеют
+func NewLogoutHandler(log *slog.Logger, svc user.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.user.logout"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		id, ok := middleware.GetIdentity(r)
+		if !ok || id.SessionID == "" {
+			log.Error("bad request", slog.String("type", errNoSession.Error()))
+			response.BadRequest(w, r, errNoSession)
+			return
+		}
+
+		if err := svc.Logout(id.SessionID); err != nil {
+			log.Error("failed to logout", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("user logged out", slog.Uint64("uuid", id.UserUUID))
+
+		response.WriteJSON(w, http.StatusOK, dto.LogoutResponse{ValidationResponse: valResp.OK()})
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"calendar/internal/health"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewReadyzHandler создает обработчик GET /readyz. Отвечает 503 с отчетом
+// по проверкам, если хотя бы одна не прошла или сервер останавливается
+func NewReadyzHandler(log *slog.Logger, checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.health.ready"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		report := checker.Ready(r.Context())
+		status := http.StatusOK
+		if report.Status != health.StatusOK {
+			status = http.StatusServiceUnavailable
+			log.Warn("not ready", slog.Any("checks", report.Checks))
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		response.WriteJSON(w, status, dto.FromReport(report))
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/user"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewRegisterHandler создает обработчик POST /register. Пароль хранится
+// только как bcrypt хеш, в ответ не возвращается. Маршрут доступен только
+// администратору, пользователь создается в его организации
+func NewRegisterHandler(log *slog.Logger, svc user.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.user.register"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.RegisterRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		u, err := svc.Register(r.Context(), req.Email, req.Name, req.Password)
+		if err != nil {
+			log.Error("failed to register user", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("user registered", slog.Uint64("uuid", u.UUID))
+
+		res := dto.FromUser(u)
+		response.WriteJSON(w, http.StatusOK, dto.RegisterResponse{
+			ValidationResponse: valResp.OK(),
+			User:               &res,
+		})
+	}
+}
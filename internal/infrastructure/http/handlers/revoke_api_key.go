@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"calendar/internal/apikey"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewRevokeAPIKeyHandler создает обработчик POST /admin/revoke_api_key.
+// Отозванный ключ остается в списке с revokedAt
+func NewRevokeAPIKeyHandler(log *slog.Logger, svc apikey.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.api_key.revoke"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.RevokeAPIKeyRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := svc.Revoke(r.Context(), req.ID); err != nil {
+			log.Error("failed to revoke api key", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("api key revoked", slog.Uint64("id", req.ID))
+
+		response.WriteJSON(w, http.StatusOK, dto.RevokeAPIKeyResponse{
+			ValidationResponse: valResp.OK(),
+			ID:                 req.ID,
+		})
+	}
+}
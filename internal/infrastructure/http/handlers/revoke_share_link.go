@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/sharelink"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewRevokeShareLinkHandler создает обработчик POST /revoke_share_link.
+// Лента отозванной ссылки отвечает 410
+func NewRevokeShareLinkHandler(log *slog.Logger, svc sharelink.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.share_link.revoke"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.RevokeShareLinkRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := svc.Revoke(r.Context(), req.ID); err != nil {
+			log.Error("failed to revoke share link", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("share link revoked", slog.Uint64("id", req.ID))
+
+		response.WriteJSON(w, http.StatusOK, dto.RevokeShareLinkResponse{
+			ValidationResponse: valResp.OK(),
+			ID:                 req.ID,
+		})
+	}
+}
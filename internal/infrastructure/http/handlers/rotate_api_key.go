@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"calendar/internal/apikey"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewRotateAPIKeyHandler создает обработчик POST /admin/rotate_api_key.
+// Старый ключ перестает действовать сразу, новый возвращается в ответе
+func NewRotateAPIKeyHandler(log *slog.Logger, svc apikey.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.api_key.rotate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.RotateAPIKeyRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		k, plain, err := svc.Rotate(r.Context(), req.ID)
+		if err != nil {
+			log.Error("failed to rotate api key", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("api key rotated", slog.Uint64("id", k.ID), slog.String("prefix", k.Prefix))
+
+		apiKeyResponseOK(w, k, plain)
+	}
+}
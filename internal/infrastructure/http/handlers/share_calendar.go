@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"calendar/internal/acl"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewShareCalendarHandler создает обработчик POST /share_calendar.
+// Повторная выдача заменяет роль, менять доступ может только владелец
+func NewShareCalendarHandler(log *slog.Logger, svc acl.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.acl.grant"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.ShareCalendarRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		g := req.ToGrant()
+		if err := svc.Grant(r.Context(), g); err != nil {
+			log.Error("failed to share calendar", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("calendar shared",
+			slog.Uint64("calendar", g.CalendarUUID),
+			slog.Uint64("user", g.UserUUID),
+			slog.String("role", g.Role.String()),
+		)
+
+		grant := dto.FromGrant(g)
+		response.WriteJSON(w, http.StatusOK, dto.ShareCalendarResponse{
+			ValidationResponse: valResp.OK(),
+			Grant:              &grant,
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/sharelink"
+	"calendar/pkg/ical"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+var errMissingTokenParam = errors.New("missing token parameter")
+
+// NewSharedEventsHandler создает публичный обработчик
+// GET /shared?token=<token>[&from=YYYY-MM-DD][&to=YYYY-MM-DD], не требующий
+// аутентификации. to включительно. Формат ответа JSON как у events_for_*,
+// либо VEVENT если клиент запросил text/calendar через Accept или format=ics
+func NewSharedEventsHandler(log *slog.Logger, svc sharelink.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		const op = "handlers.share_link.events"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		q := r.URL.Query()
+		token := q.Get("token")
+		if token == "" {
+			log.Error("bad request", slog.String("type", errMissingTokenParam.Error()))
+			response.BadRequest(w, r, errMissingTokenParam)
+			return
+		}
+		var from, to time.Time
+		var err error
+		if s := q.Get("from"); s != "" {
+			if from, err = time.Parse(time.DateOnly, s); err != nil {
+				log.Error("bad request", slog.String("type", errInvalidDateFormat.Error()), sl.Err(err))
+				response.BadRequest(w, r, errInvalidDateFormat)
+				return
+			}
+		}
+		if s := q.Get("to"); s != "" {
+			if to, err = time.Parse(time.DateOnly, s); err != nil {
+				log.Error("bad request", slog.String("type", errInvalidDateFormat.Error()), sl.Err(err))
+				response.BadRequest(w, r, errInvalidDateFormat)
+				return
+			}
+			to = to.AddDate(0, 0, 1)
+		}
+
+		l, events, err := svc.Events(r.Context(), token, from, to)
+		if err != nil {
+			log.Error("failed to get shared events", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("shared events served", slog.Uint64("link", l.ID), slog.Int("count", len(events)))
+
+		// лента кешируется календарными клиентами, но не общими прокси
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if wantsICal(r) {
+			sharedEventsResponseICal(w, l, events)
+			return
+		}
+		// напоминания владельца гостям не нужны
+		for i := range events {
+			events[i].Reminders = nil
+		}
+		response.WriteJSON(w, http.StatusOK, dto.GetEventResponse{
+			ValidationResponse: valResp.OK(),
+			Events:             dto.FromEvents(events),
+		})
+	}
+}
+
+func sharedEventsResponseICal(w http.ResponseWriter, l sharelink.Link, events []event.Event) {
+	cal := ical.Calendar{
+		Name:   fmt.Sprintf("Calendar %d", l.CalendarUUID),
+		Events: make([]ical.Event, 0, len(events)),
+	}
+	for _, e := range events {
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("event-%d@calendar", e.UUID),
+			Start:       e.Date,
+			End:         e.EndTime(),
+			Summary:     e.Title,
+			Description: e.Desc,
+		})
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.WriteHeader(http.StatusOK)
+	cal.WriteTo(w)
+}
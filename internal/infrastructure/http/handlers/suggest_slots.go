@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/scheduling"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+var errInvalidWorkingHours = errors.New("invalid working hours")
+
+// NewSuggestSlotsHandler создает обработчик POST /suggest_slots, предлагающий
+// общие свободные слоты для встречи участников
+func NewSuggestSlotsHandler(log *slog.Logger, svc scheduling.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.scheduling.suggest"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.SuggestSlotsRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		schedReq, err := toSchedulingRequest(req)
+		if err != nil {
+			log.Error("bad request", slog.String("type", errInvalidWorkingHours.Error()), sl.Err(err))
+			response.BadRequest(w, r, err)
+			return
+		}
+
+		slots, err := svc.FindSlots(r.Context(), schedReq)
+		if err != nil {
+			log.Error("failed to find slots", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("slots found", slog.Int("count", len(slots)))
+
+		suggestSlotsResponseOK(w, slots)
+	}
+}
+
+func toSchedulingRequest(req dto.SuggestSlotsRequest) (scheduling.Request, error) {
+	res := scheduling.Request{
+		Participants: req.Participants,
+		Duration:     time.Duration(req.DurationMinutes) * time.Minute,
+		From:         req.From,
+		To:           req.To,
+		WorkingHours: scheduling.DefaultWorkingHours(),
+		Buffer:       time.Duration(req.BufferMinutes) * time.Minute,
+		Step:         time.Duration(req.StepMinutes) * time.Minute,
+		Limit:        req.Limit,
+		Rank:         scheduling.Rank(req.Rank),
+	}
+
+	if req.WorkingHours != nil {
+		wh, err := toWorkingHours(*req.WorkingHours)
+		if err != nil {
+			return res, err
+		}
+		res.WorkingHours = wh
+	}
+
+	if len(req.ParticipantHours) > 0 {
+		res.PerUser = make(map[uint64]scheduling.WorkingHours, len(req.ParticipantHours))
+		for user, h := range req.ParticipantHours {
+			wh, err := toWorkingHours(h)
+			if err != nil {
+				return res, fmt.Errorf("user %d: %w", user, err)
+			}
+			res.PerUser[user] = wh
+		}
+	}
+
+	return res, nil
+}
+
+func toWorkingHours(h dto.WorkingHours) (scheduling.WorkingHours, error) {
+	var wh scheduling.WorkingHours
+
+	start, err := dto.ParseClock(h.Start)
+	if err != nil {
+		return wh, fmt.Errorf("%w: %v", errInvalidWorkingHours, err)
+	}
+	end, err := dto.ParseClock(h.End)
+	if err != nil {
+		return wh, fmt.Errorf("%w: %v", errInvalidWorkingHours, err)
+	}
+
+	loc := time.UTC
+	if h.TimeZone != "" {
+		if loc, err = time.LoadLocation(h.TimeZone); err != nil {
+			return wh, fmt.Errorf("%w: %v", errInvalidWorkingHours, err)
+		}
+	}
+
+	wh = scheduling.WorkingHours{Start: start, End: end, Location: loc}
+	for _, d := range h.Weekdays {
+		wh.Weekdays = append(wh.Weekdays, time.Weekday(d))
+	}
+	if len(wh.Weekdays) == 0 {
+		wh.Weekdays = scheduling.DefaultWorkingHours().Weekdays
+	}
+	return wh, nil
+}
+
+func suggestSlotsResponseOK(w http.ResponseWriter, slots []scheduling.Slot) {
+	r := dto.SuggestSlotsResponse{
+		ValidationResponse: valResp.OK(),
+		Slots:              make([]dto.Slot, 0, len(slots)),
+	}
+	for _, s := range slots {
+		r.Slots = append(r.Slots, dto.Slot{
+			Start:                s.Start,
+			End:                  s.End,
+			FragmentationMinutes: int(s.Fragmentation / time.Minute),
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, r)
+}
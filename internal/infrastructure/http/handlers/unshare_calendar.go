@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"calendar/internal/acl"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/pkg/sl_logger/sl"
+	valResp "calendar/pkg/validator"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewUnshareCalendarHandler создает обработчик POST /unshare_calendar.
+// Последнего владельца календаря отозвать нельзя
+func NewUnshareCalendarHandler(log *slog.Logger, svc acl.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.acl.revoke"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.UnshareCalendarRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := svc.Revoke(r.Context(), req.CalendarUUID, req.Principal()); err != nil {
+			log.Error("failed to unshare calendar", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("calendar unshared",
+			slog.Uint64("calendar", req.CalendarUUID),
+			slog.Uint64("user", req.Principal()),
+		)
+
+		response.WriteJSON(w, http.StatusOK, dto.UnshareCalendarResponse{
+			ValidationResponse: valResp.OK(),
+			CalendarUUID:       req.CalendarUUID,
+		})
+	}
+}
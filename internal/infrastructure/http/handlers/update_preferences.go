@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/response"
+	"calendar/internal/preferences"
+	"calendar/pkg/sl_logger/sl"
+
+	"log/slog"
+	"net/http"
+)
+
+// NewUpdatePreferencesHandler создает обработчик POST /update_preferences,
+// заменяющий профиль доступности пользователя целиком
+func NewUpdatePreferencesHandler(log *slog.Logger, svc preferences.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		const op = "handlers.preferences.update"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetRequestID(r)),
+		)
+
+		req, ok := decodeRequest[dto.PreferencesRequest](log, w, r)
+		if !ok {
+			return
+		}
+
+		if req.UserUUID, ok = profileOwner(log, w, r, req.UserUUID); !ok {
+			return
+		}
+
+		profile, err := dto.ToProfile(req)
+		if err != nil {
+			log.Error("invalid request", sl.Err(err))
+			response.BadRequest(w, r, err)
+			return
+		}
+
+		if err := svc.Update(r.Context(), profile); err != nil {
+			log.Error("failed to update preferences", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		log.Info("preferences updated", slog.Uint64("user", profile.UserUUID))
+
+		preferencesResponseOK(w, profile)
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	valResp "calendar/pkg/validator"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validateRequest проверяет запрос по тегам validate. Используется и HTTP,
+// и WebSocket обработчиками, чтобы правила и формат ошибок совпадали.
+// Сообщения об ошибках пишутся на языке lang
+func validateRequest(req any, lang valResp.Lang) (valResp.ValidationResponse, error) {
+	if err := valResp.Struct(req); err != nil {
+		validateErr, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return valResp.Error(err.Error()), err
+		}
+		return valResp.ValidationErrorLang(validateErr, lang), err
+	}
+	return valResp.OK(), nil
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"calendar/internal/event"
+	dto "calendar/internal/infrastructure/http/handlers/dto"
+	"calendar/internal/preferences"
+
+	"context"
+	"net/http"
+	"slices"
+)
+
+const annotateWorkingHoursParam = "working_hours"
+
+func wantsWorkingHoursAnnotation(r *http.Request) bool {
+	return r.URL.Query().Get("annotate") == annotateWorkingHoursParam
+}
+
+// annotateWorkingHours помечает события, выходящие за рабочее время владельца.
+// События пользователей без профиля доступности остаются без пометки
+func annotateWorkingHours(ctx context.Context, prefs preferences.Service, events []event.Event, res []dto.UserEvent) error {
+	users := make([]uint64, 0, len(events))
+	for _, e := range events {
+		if !slices.Contains(users, e.UserUUID) {
+			users = append(users, e.UserUUID)
+		}
+	}
+	// профили владельцев чужих событий в ответ не попадают, только пометка
+	profiles, err := prefs.GetMany(ctx, users)
+	if err != nil {
+		return err
+	}
+	for i, e := range events {
+		p, ok := profiles[e.UserUUID]
+		if !ok {
+			continue
+		}
+		outside := !p.IsWorking(e.Date, e.EndTime())
+		res[i].OutsideWorkingHours = &outside
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/tenant"
+	"calendar/pkg/sl_logger/sl"
+
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Схемы заголовка Authorization
+const (
+	SchemeBearer = "Bearer"
+	SchemeAPIKey = "ApiKey"
+)
+
+// TokenVerifier проверяет учетные данные одной схемы и возвращает
+// пользователя
+type TokenVerifier interface {
+	Verify(token string) (auth.Identity, error)
+}
+
+// ErrorWriter пишет ответ с ошибкой, обычно response.Error. Передается
+// снаружи, потому что пакет response сам зависит от middleware
+type ErrorWriter func(w http.ResponseWriter, r *http.Request, err error) error
+
+// accessTokenParam параметр строки запроса из RFC 6750 для клиентов, которые
+// не могут выставить заголовок: WebSocket и EventSource в браузере
+const accessTokenParam = "access_token"
+
+// Authenticate требует заголовок Authorization: <scheme> <credentials> со
+// схемой из verifiers и кладет пользователя и его организацию в контекст
+// запроса. Для GET
+// bearer токен можно передать в параметре access_token. Без verifiers
+// запросы пропускаются как есть
+func Authenticate(log *slog.Logger, verifiers map[string]TokenVerifier, fail ErrorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(verifiers) == 0 {
+			return next
+		}
+		log := log.With(slog.String("component", "middleware/auth"))
+
+		challenge := make([]string, 0, len(verifiers))
+		for scheme := range verifiers {
+			challenge = append(challenge, scheme+` realm="calendar"`)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := authenticate(r, verifiers)
+			if err != nil {
+				log.Warn("authentication failed",
+					slog.String("request_id", GetRequestID(r)),
+					sl.Err(err),
+				)
+				for _, c := range challenge {
+					w.Header().Add("WWW-Authenticate", c)
+				}
+				fail(w, r, err)
+				return
+			}
+			ctx := auth.WithIdentity(r.Context(), id)
+			next.ServeHTTP(w, r.WithContext(tenant.WithOrg(ctx, id.OrgUUID)))
+		})
+	}
+}
+
+// RequireScope пропускает только пользователей с правом scope, см.
+// auth.Require. Ставится после Authenticate
+func RequireScope(scope auth.Scope, fail ErrorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := auth.Require(r.Context(), scope); err != nil {
+				fail(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Optional применяет mw только к запросам с учетными данными, анонимные
+// запросы передаются в next как есть
+func Optional(mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		guarded := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" && r.URL.Query().Get(accessTokenParam) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			guarded.ServeHTTP(w, r)
+		})
+	}
+}
+
+func authenticate(r *http.Request, verifiers map[string]TokenVerifier) (auth.Identity, error) {
+	scheme, credentials, err := credentials(r)
+	if err != nil {
+		return auth.Identity{}, err
+	}
+	for s, v := range verifiers {
+		if strings.EqualFold(s, scheme) {
+			return v.Verify(credentials)
+		}
+	}
+	return auth.Identity{}, fmt.Errorf("%w: unsupported scheme %q", auth.ErrUnauthenticated, scheme)
+}
+
+func credentials(r *http.Request) (string, string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		if token := r.URL.Query().Get(accessTokenParam); token != "" && r.Method == http.MethodGet {
+			return SchemeBearer, token, nil
+		}
+		return "", "", fmt.Errorf("%w: missing authorization header", auth.ErrUnauthenticated)
+	}
+
+	scheme, creds, ok := strings.Cut(header, " ")
+	creds = strings.TrimSpace(creds)
+	if !ok || creds == "" {
+		return "", "", fmt.Errorf("%w: malformed authorization header", auth.ErrUnauthenticated)
+	}
+	return scheme, creds, nil
+}
+
+// GetIdentity возвращает пользователя, проверенного Authenticate
+func GetIdentity(r *http.Request) (auth.Identity, bool) {
+	return auth.FromContext(r.Context())
+}
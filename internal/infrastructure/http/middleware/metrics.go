@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// unmatchedRoute метка запросов, не попавших ни в один маршрут, чтобы
+// произвольные пути не раздували число рядов
+const unmatchedRoute = "unmatched"
+
+// HTTPObserver принимает измерения запросов, обычно metrics.HTTP
+type HTTPObserver interface {
+	Started()
+	Finished(route, method string, status int, d time.Duration)
+}
+
+// Metrics измеряет запросы по маршрутам. Оборачивает mux целиком: маршрут
+// берется из r.Pattern, который ServeMux заполняет при выборе обработчика
+func Metrics(observer HTTPObserver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := NewWrapResponseWriter(w)
+			start := time.Now()
+			observer.Started()
+
+			defer func() {
+				route := r.Pattern
+				if route == "" {
+					route = unmatchedRoute
+				}
+				observer.Finished(route, r.Method, ww.Status(), time.Since(start))
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"calendar/internal/ratelimit"
+
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitPolicy лимит по умолчанию и лимиты отдельных маршрутов по
+// пути. Маршрут со своим лимитом получает отдельную корзину. KeyBy одно
+// из ratelimit.KeyByAPIKey, KeyByUser, KeyByIP
+type RateLimitPolicy struct {
+	KeyBy   string
+	Default ratelimit.Limit
+	Routes  map[string]ratelimit.Limit
+}
+
+// RateLimit отвечает 429 с Retry-After, когда у клиента кончились токены.
+// Заголовки RateLimit-Limit, RateLimit-Remaining и RateLimit-Reset
+// выставляются на каждый ответ. Ставится после Authenticate, чтобы
+// клиента можно было узнать по учетным данным. Перед Authenticate ставится
+// политика с ratelimit.KeyByIP на отдельном Limiter, чтобы запросы с
+// неверными учетными данными тоже ограничивались
+func RateLimit(log *slog.Logger, limiter ratelimit.Limiter, policy RateLimitPolicy, fail ErrorWriter) func(http.Handler) http.Handler {
+	log = log.With(slog.String("component", "middleware/rate_limit"))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := clientKey(r, policy.KeyBy)
+			limit, ok := policy.Routes[r.URL.Path]
+			if ok {
+				key += " " + r.URL.Path
+			} else {
+				limit = policy.Default
+			}
+			if limit.Unlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			d := limiter.Allow(key, limit)
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(d.Reset))
+			if !d.Allowed {
+				log.Warn("rate limit exceeded",
+					slog.String("request_id", GetRequestID(r)),
+					slog.String("key", key),
+				)
+				// клиент не должен повторять запрос сразу
+				h.Set("Retry-After", ceilSeconds(max(d.RetryAfter, time.Second)))
+				fail(w, r, ratelimit.ErrLimited)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientKey(r *http.Request, keyBy string) string {
+	id, ok := GetIdentity(r)
+	if ok && keyBy == ratelimit.KeyByAPIKey && id.Method == "api_key" {
+		return id.Subject
+	}
+	if ok && keyBy != ratelimit.KeyByIP {
+		return fmt.Sprintf("user:%d:%d", id.OrgUUID, id.UserUUID)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ceilSeconds целые секунды для заголовков с округлением вверх
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -1,6 +1,10 @@
 package middleware
 
-import "net/http"
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
 
 type responseWriter struct {
 	http.ResponseWriter
@@ -30,3 +34,14 @@ func (rw *responseWriter) Status() int {
 func (rw *responseWriter) BytesWritten() int {
 	return rw.bytesWritten
 }
+
+// Unwrap позволяет http.ResponseController добраться до исходного writer
+// (Flush, SetWriteDeadline для потоковых ответов)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Hijack нужен для перехода соединения на WebSocket
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
+}
@@ -0,0 +1,28 @@
+package openapi
+
+import (
+	"net/http"
+)
+
+// Mux http.ServeMux, запоминающий зарегистрированные шаблоны для Spec.Verify
+type Mux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func NewMux() *Mux {
+	return &Mux{ServeMux: http.NewServeMux()}
+}
+
+func (m *Mux) Handle(pattern string, handler http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.Handle(pattern, handler)
+}
+
+func (m *Mux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+func (m *Mux) Patterns() []string {
+	return m.patterns
+}
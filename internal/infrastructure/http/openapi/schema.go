@@ -0,0 +1,218 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas строит схемы по Go типам через reflect, именованные структуры
+// попадают в components и подставляются через $ref
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+func (s *schemas) of(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}, nil
+	case rawMessageType:
+		return &Schema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: ptr(0.0)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := s.of(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		values, err := s.of(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return s.ref(t)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+}
+
+func (s *schemas) ref(t reflect.Type) (*Schema, error) {
+	if t.Name() == "" {
+		return s.object(t)
+	}
+
+	name, ok := s.names[t]
+	if !ok {
+		name = s.name(t)
+		s.names[t] = name
+		// заглушка до построения, чтобы рекурсивные типы не зациклились
+		s.components[name] = &Schema{}
+		obj, err := s.object(t)
+		if err != nil {
+			return nil, err
+		}
+		*s.components[name] = *obj
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}, nil
+}
+
+// name имя типа, при совпадении имен из разных пакетов добавляется пакет
+func (s *schemas) name(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := s.components[name]; !taken {
+		return name
+	}
+	pkg := path.Base(t.PkgPath())
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + name
+}
+
+func (s *schemas) object(t reflect.Type) (*Schema, error) {
+	obj := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := s.fields(t, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// fields добавляет поля структуры в obj, встроенные структуры без тега json
+// раскрываются так же, как это делает encoding/json
+func (s *schemas) fields(t reflect.Type, obj *Schema) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := s.fields(ft, obj); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := s.of(f.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		if applyValidate(prop, f.Tag.Get("validate")) {
+			obj.Required = append(obj.Required, name)
+		}
+		obj.Properties[name] = prop
+	}
+	return nil
+}
+
+// applyValidate переносит в схему правила тега validate, которые выражаются
+// в OpenAPI, и сообщает, обязательно ли поле. Правила после dive относятся
+// к элементам коллекции
+func applyValidate(prop *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required := false
+	target := prop
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			if target == prop {
+				required = true
+			}
+		case "dive":
+			switch {
+			case target.Items != nil:
+				target = target.Items
+			case target.AdditionalProperties != nil:
+				target = target.AdditionalProperties
+			}
+		case "min", "max":
+			applyBound(target, key, param)
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "url":
+			target.Format = "uri"
+		case "email":
+			target.Format = "email"
+		}
+	}
+	return required
+}
+
+func applyBound(s *Schema, key, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil || s.Ref != "" {
+		return
+	}
+	switch {
+	case s.Type == "array" && key == "min":
+		s.MinItems = ptr(int(n))
+	case s.Type == "string" && key == "min":
+		s.MinLength = ptr(int(n))
+	case s.Type == "integer" || s.Type == "number":
+		if key == "min" {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
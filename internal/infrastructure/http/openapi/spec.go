@@ -0,0 +1,239 @@
+// Package openapi строит документ OpenAPI 3 по описаниям маршрутов и
+// DTO типам обработчиков и проверяет, что описаны все зарегистрированные пути
+package openapi
+
+import (
+	resp "calendar/internal/infrastructure/http/response"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Version         = "3.0.3"
+	ContentTypeJSON = "application/json"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrDuplicate       = errors.New("duplicate operation")
+	ErrUndocumented    = errors.New("route is not documented")
+	ErrNotRegistered   = errors.New("documented route is not registered")
+	ErrUnknownScheme   = errors.New("unknown security scheme")
+)
+
+// Operation описание одного маршрута. Request и Body в Response задаются
+// нулевыми значениями DTO, например dto.AddEventRequest{}
+type Operation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tag         string
+	Query       []Param
+	Request     any
+	Responses   []Response
+	// Security имена схем из SecuritySchemes, любой из них подходит.
+	// Пустой список означает публичный маршрут
+	Security []string
+}
+
+// Param параметр строки запроса, Type и Format как в JSON Schema
+type Param struct {
+	Name        string
+	Type        string
+	Format      string
+	Required    bool
+	Description string
+}
+
+// Response ответ операции. Пустой ContentType означает JSON, для других
+// типов без Body схема тела строка
+type Response struct {
+	Status      int
+	Description string
+	ContentType string
+	Body        any
+}
+
+// SecuritySchemes схемы аутентификации по имени
+type SecuritySchemes map[string]SecurityScheme
+
+// Spec готовый документ и его JSON представление
+type Spec struct {
+	doc  Document
+	raw  []byte
+	path map[string]bool
+}
+
+func New(info Info, schemes SecuritySchemes, ops []Operation) (*Spec, error) {
+	const op = "openapi.new"
+
+	s := newSchemas()
+	doc := Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+	paths := make(map[string]bool, len(ops))
+
+	for _, o := range ops {
+		method := strings.ToLower(o.Method)
+		item, ok := doc.Paths[o.Path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[o.Path] = item
+		}
+		if _, ok := item[method]; ok {
+			return nil, fmt.Errorf("%s: %w: %s %s", op, ErrDuplicate, o.Method, o.Path)
+		}
+
+		obj, err := s.operation(o)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s %s: %w", op, o.Method, o.Path, err)
+		}
+		for _, name := range o.Security {
+			if _, ok := schemes[name]; !ok {
+				return nil, fmt.Errorf("%s: %s %s: %w: %s", op, o.Method, o.Path, ErrUnknownScheme, name)
+			}
+			obj.Security = append(obj.Security, map[string][]string{name: {}})
+		}
+		item[method] = obj
+		paths[o.Path] = true
+	}
+	doc.Components.Schemas = s.components
+	doc.Components.SecuritySchemes = schemes
+
+	raw, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &Spec{doc: doc, raw: raw, path: paths}, nil
+}
+
+// MustNew как New, но паникует: ошибка означает неверное описание маршрутов
+func MustNew(info Info, schemes SecuritySchemes, ops []Operation) *Spec {
+	s, err := New(info, schemes, ops)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *schemas) operation(o Operation) (*operationObject, error) {
+	obj := &operationObject{
+		Summary:     o.Summary,
+		Description: o.Description,
+		OperationID: strings.ToLower(o.Method) + strings.ReplaceAll(o.Path, "/", "_"),
+		Responses:   make(map[string]response, len(o.Responses)),
+	}
+	if o.Tag != "" {
+		obj.Tags = []string{o.Tag}
+	}
+
+	for _, p := range o.Query {
+		obj.Parameters = append(obj.Parameters, parameter{
+			Name:        p.Name,
+			In:          "query",
+			Required:    p.Required,
+			Description: p.Description,
+			Schema:      &Schema{Type: p.Type, Format: p.Format},
+		})
+	}
+
+	if o.Request != nil {
+		schema, err := s.of(reflect.TypeOf(o.Request))
+		if err != nil {
+			return nil, err
+		}
+		obj.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]mediaType{ContentTypeJSON: {Schema: schema}},
+		}
+	}
+
+	for _, r := range o.Responses {
+		// ответы с одним статусом и разными типами содержимого объединяются
+		status := strconv.Itoa(r.Status)
+		res, ok := obj.Responses[status]
+		if !ok {
+			res = response{Description: r.Description}
+		}
+		if res.Description == "" {
+			res.Description = http.StatusText(r.Status)
+		}
+
+		contentType := r.ContentType
+		if contentType == "" && r.Body != nil {
+			contentType = ContentTypeJSON
+		}
+		if contentType != "" {
+			schema := &Schema{Type: "string"}
+			if r.Body != nil {
+				var err error
+				if schema, err = s.of(reflect.TypeOf(r.Body)); err != nil {
+					return nil, err
+				}
+			}
+			if res.Content == nil {
+				res.Content = make(map[string]mediaType)
+			}
+			res.Content[contentType] = mediaType{Schema: schema}
+		}
+		obj.Responses[status] = res
+	}
+	return obj, nil
+}
+
+func (s *Spec) Document() Document {
+	return s.doc
+}
+
+// Verify сверяет описанные пути с зарегистрированными шаблонами и
+// возвращает все расхождения сразу
+func (s *Spec) Verify(patterns []string) error {
+	registered := make(map[string]bool, len(patterns))
+	var errs []error
+
+	for _, p := range patterns {
+		// шаблоны вида "GET /path" из ServeMux Go 1.22
+		if _, path, ok := strings.Cut(p, " "); ok {
+			p = path
+		}
+		registered[p] = true
+		if !s.path[p] {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUndocumented, p))
+		}
+	}
+
+	documented := make([]string, 0, len(s.path))
+	for p := range s.path {
+		documented = append(documented, p)
+	}
+	sort.Strings(documented)
+	for _, p := range documented {
+		if !registered[p] {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrNotRegistered, p))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Handler отдает документ по GET
+func (s *Spec) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp.MethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.WriteHeader(http.StatusOK)
+		w.Write(s.raw)
+	}
+}
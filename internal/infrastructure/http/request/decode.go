@@ -0,0 +1,91 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	ContentTypeJSON = "application/json"
+	// MaxBodyBytes предел тела JSON запроса
+	MaxBodyBytes int64 = 1 << 20
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("content type must be application/json")
+	ErrBodyTooLarge         = errors.New("request body is too large")
+	ErrUnknownField         = errors.New("unknown field")
+)
+
+// DecodeJSON читает тело запроса в dst: проверяет Content-Type, ограничивает
+// размер тела, отклоняет неизвестные поля и данные после JSON значения.
+// Пустой Content-Type допускается для совместимости со старыми клиентами.
+// Ошибки оборачивают один из Err*, причину стоит писать только в лог,
+// клиенту отдается Message(err)
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaType != ContentTypeJSON {
+			return fmt.Errorf("%w: %q", ErrUnsupportedMediaType, ct)
+		}
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: unexpected data after JSON value", ErrFailedToDecodeReqBody)
+	}
+	return nil
+}
+
+func decodeError(err error) error {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: %v", ErrEmptyReqBody, err)
+	case errors.As(err, &maxErr):
+		return fmt.Errorf("%w: limit %d bytes", ErrBodyTooLarge, maxErr.Limit)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json не экспортирует тип этой ошибки
+		return fmt.Errorf("%w %s", ErrUnknownField, strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return fmt.Errorf("%w: %v", ErrFailedToDecodeReqBody, err)
+	}
+}
+
+// Status HTTP статус для ошибки DecodeJSON
+func Status(err error) int {
+	switch {
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrBodyTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// Message текст ошибки DecodeJSON для клиента без деталей разбора
+func Message(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownField):
+		return err.Error()
+	case errors.Is(err, ErrEmptyReqBody):
+		return ErrEmptyReqBody.Error()
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return ErrUnsupportedMediaType.Error()
+	case errors.Is(err, ErrBodyTooLarge):
+		return ErrBodyTooLarge.Error()
+	default:
+		return ErrFailedToDecodeReqBody.Error()
+	}
+}
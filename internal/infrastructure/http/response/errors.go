@@ -1,2 +1,215 @@
 // Package response provides
 package response
+
+import (
+	"calendar/internal/acl"
+	"calendar/internal/apikey"
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	"calendar/internal/infrastructure/http/middleware"
+	"calendar/internal/infrastructure/http/request"
+	"calendar/internal/preferences"
+	"calendar/internal/ratelimit"
+	"calendar/internal/scheduling"
+	"calendar/internal/sharelink"
+	"calendar/internal/tenant"
+	"calendar/internal/user"
+	"calendar/internal/webhook"
+
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const ContentTypeProblem = "application/problem+json"
+
+// Code стабильный машиночитаемый код ошибки, клиенты ветвятся по нему,
+// а не по тексту detail
+type Code string
+
+const (
+	CodeMethodNotAllowed     Code = "method_not_allowed"
+	CodeEmptyBody            Code = "empty_body"
+	CodeMalformedBody        Code = "malformed_body"
+	CodeUnknownField         Code = "unknown_field"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodeBodyTooLarge         Code = "body_too_large"
+	CodeValidationFailed     Code = "validation_failed"
+	CodeInvalidArgument      Code = "invalid_argument"
+	CodeUnauthorized         Code = "unauthorized"
+	CodeNotFound             Code = "not_found"
+	CodeConflict             Code = "conflict"
+	CodeForbidden            Code = "forbidden"
+	CodeGone                 Code = "gone"
+	CodeQuotaExceeded        Code = "quota_exceeded"
+	CodeRateLimited          Code = "rate_limited"
+	CodeInternal             Code = "internal"
+)
+
+// problemTypeBase префикс поля type, к нему добавляется код
+const problemTypeBase = "urn:calendar:problem:"
+
+// Problem ошибка в формате RFC 7807 с расширениями code, requestId и errors
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      Code              `json:"code"`
+	RequestID string            `json:"requestId,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// errorMap единственное место, где ошибки сервисов и разбора запроса
+// сопоставляются со статусами и кодами. Порядок важен: побеждает первое
+// совпадение по errors.Is
+var errorMap = []struct {
+	err    error
+	status int
+	code   Code
+}{
+	{request.ErrEmptyReqBody, http.StatusBadRequest, CodeEmptyBody},
+	{request.ErrUnknownField, http.StatusBadRequest, CodeUnknownField},
+	{request.ErrFailedToDecodeReqBody, http.StatusBadRequest, CodeMalformedBody},
+	{request.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType},
+	{request.ErrBodyTooLarge, http.StatusRequestEntityTooLarge, CodeBodyTooLarge},
+
+	{auth.ErrUnauthenticated, http.StatusUnauthorized, CodeUnauthorized},
+	{auth.ErrInsufficientScope, http.StatusForbidden, CodeForbidden},
+
+	{event.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{event.ErrConflict, http.StatusConflict, CodeConflict},
+	{event.ErrValidation, http.StatusBadRequest, CodeInvalidArgument},
+	{event.ErrForbidden, http.StatusForbidden, CodeForbidden},
+
+	{acl.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{acl.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{acl.ErrLastOwner, http.StatusConflict, CodeConflict},
+	{acl.ErrInvalidGrant, http.StatusBadRequest, CodeInvalidArgument},
+	{acl.ErrNoCalendar, http.StatusBadRequest, CodeInvalidArgument},
+
+	{preferences.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{preferences.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{preferences.ErrExists, http.StatusConflict, CodeConflict},
+	{preferences.ErrInvalidTimeZone, http.StatusBadRequest, CodeInvalidArgument},
+	{preferences.ErrInvalidWorkingHours, http.StatusBadRequest, CodeInvalidArgument},
+	{preferences.ErrInvalidOutOfOffice, http.StatusBadRequest, CodeInvalidArgument},
+
+	{scheduling.ErrInvalidDuration, http.StatusBadRequest, CodeInvalidArgument},
+	{scheduling.ErrInvalidRange, http.StatusBadRequest, CodeInvalidArgument},
+	{scheduling.ErrRangeTooLong, http.StatusBadRequest, CodeInvalidArgument},
+	{scheduling.ErrInvalidWorkingHours, http.StatusBadRequest, CodeInvalidArgument},
+	{scheduling.ErrNoParticipants, http.StatusBadRequest, CodeInvalidArgument},
+
+	{apikey.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{apikey.ErrRevoked, http.StatusConflict, CodeConflict},
+	{apikey.ErrInvalidScope, http.StatusBadRequest, CodeInvalidArgument},
+
+	{user.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{user.ErrExists, http.StatusConflict, CodeConflict},
+	{user.ErrInvalidCredentials, http.StatusUnauthorized, CodeUnauthorized},
+	{user.ErrInvalidPassword, http.StatusBadRequest, CodeInvalidArgument},
+	{user.ErrLoginDisabled, http.StatusForbidden, CodeForbidden},
+	{user.ErrSessionNotFound, http.StatusNotFound, CodeNotFound},
+
+	{sharelink.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{sharelink.ErrRevoked, http.StatusGone, CodeGone},
+	{sharelink.ErrExpired, http.StatusGone, CodeGone},
+	{sharelink.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{sharelink.ErrNoCalendar, http.StatusBadRequest, CodeInvalidArgument},
+	{sharelink.ErrInvalidRange, http.StatusBadRequest, CodeInvalidArgument},
+	{sharelink.ErrInvalidExpiry, http.StatusBadRequest, CodeInvalidArgument},
+
+	{tenant.ErrQuotaExceeded, http.StatusForbidden, CodeQuotaExceeded},
+
+	{ratelimit.ErrLimited, http.StatusTooManyRequests, CodeRateLimited},
+
+	{webhook.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{webhook.ErrInvalidURL, http.StatusBadRequest, CodeInvalidArgument},
+	{webhook.ErrForbiddenHost, http.StatusBadRequest, CodeInvalidArgument},
+	{webhook.ErrInvalidType, http.StatusBadRequest, CodeInvalidArgument},
+}
+
+// FromError строит Problem по ошибке. Неизвестные ошибки становятся 500
+// без подробностей, их текст остается только в логах
+func FromError(err error) Problem {
+	for _, m := range errorMap {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+		detail := err.Error()
+		switch {
+		case isRequestError(err):
+			// ошибки разбора тела содержат детали encoding/json
+			detail = request.Message(err)
+		case m.code == CodeNotFound || m.code == CodeConflict || m.code == CodeForbidden ||
+			m.code == CodeUnauthorized || m.code == CodeGone || m.code == CodeQuotaExceeded:
+			// текст хранилища с op и ключом, как и причина отказа в
+			// аутентификации, клиенту ни к чему
+			detail = m.err.Error()
+		}
+		return NewProblem(m.status, m.code, detail)
+	}
+	return NewProblem(http.StatusInternalServerError, CodeInternal, "internal error")
+}
+
+func isRequestError(err error) bool {
+	for _, e := range []error{
+		request.ErrEmptyReqBody,
+		request.ErrUnknownField,
+		request.ErrFailedToDecodeReqBody,
+		request.ErrUnsupportedMediaType,
+		request.ErrBodyTooLarge,
+	} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewProblem(status int, code Code, detail string) Problem {
+	return Problem{
+		Type:   problemTypeBase + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WriteProblem дополняет p путем и ID запроса и пишет его как problem+json
+func WriteProblem(w http.ResponseWriter, r *http.Request, p Problem) error {
+	p.Instance = r.URL.Path
+	p.RequestID = middleware.GetRequestID(r)
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// Error пишет ответ для ошибки сервиса или разбора запроса
+func Error(w http.ResponseWriter, r *http.Request, err error) error {
+	return WriteProblem(w, r, FromError(err))
+}
+
+// BadRequest пишет 400 invalid_argument, например для неверных параметров
+// строки запроса
+func BadRequest(w http.ResponseWriter, r *http.Request, err error) error {
+	return WriteProblem(w, r, NewProblem(http.StatusBadRequest, CodeInvalidArgument, err.Error()))
+}
+
+// Validation пишет 400 validation_failed с ошибками по полям
+func Validation(w http.ResponseWriter, r *http.Request, fields map[string]string) error {
+	p := NewProblem(http.StatusBadRequest, CodeValidationFailed, "request validation failed")
+	p.Errors = fields
+	return WriteProblem(w, r, p)
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) error {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	return WriteProblem(w, r, NewProblem(http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+		"method "+r.Method+" is not allowed"))
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"calendar/internal/event"
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EventStorage декоратор event.Storage, измеряющий длительность и ошибки
+// каждого метода. Ошибкой считается любой ненулевой err, включая
+// ErrNotFound: по ним видно и сбои хранилища, и запросы мимо данных
+type EventStorage struct {
+	next     event.Storage
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func NewEventStorage(next event.Storage, reg prometheus.Registerer) *EventStorage {
+	s := &EventStorage{
+		next: next,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Event storage operation latency by method.",
+			Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_errors_total",
+			Help:      "Event storage operations that returned an error, by method.",
+		}, []string{"method"}),
+	}
+	reg.MustRegister(s.duration, s.errors)
+	return s
+}
+
+// observe засекает начало операции, возвращенная функция вызывается через
+// defer с именованной ошибкой метода, чтобы увидеть итоговое значение
+func (s *EventStorage) observe(method string) func(err *error) {
+	started := time.Now()
+	return func(err *error) {
+		s.duration.WithLabelValues(method).Observe(time.Since(started).Seconds())
+		if *err != nil {
+			s.errors.WithLabelValues(method).Inc()
+		}
+	}
+}
+
+func (s *EventStorage) Add(ctx context.Context, e event.Event) (id uint64, err error) {
+	defer s.observe("Add")(&err)
+	return s.next.Add(ctx, e)
+}
+
+func (s *EventStorage) Update(ctx context.Context, e event.Event) (err error) {
+	defer s.observe("Update")(&err)
+	return s.next.Update(ctx, e)
+}
+
+func (s *EventStorage) Delete(ctx context.Context, uuid uint64) (err error) {
+	defer s.observe("Delete")(&err)
+	return s.next.Delete(ctx, uuid)
+}
+
+func (s *EventStorage) Get(ctx context.Context, uuid uint64) (e event.Event, err error) {
+	defer s.observe("Get")(&err)
+	return s.next.Get(ctx, uuid)
+}
+
+func (s *EventStorage) ListByDay(ctx context.Context, t time.Time) (events []event.Event, err error) {
+	defer s.observe("ListByDay")(&err)
+	return s.next.ListByDay(ctx, t)
+}
+
+func (s *EventStorage) ListByWeek(ctx context.Context, t time.Time) (events []event.Event, err error) {
+	defer s.observe("ListByWeek")(&err)
+	return s.next.ListByWeek(ctx, t)
+}
+
+func (s *EventStorage) ListByMonth(ctx context.Context, t time.Time) (events []event.Event, err error) {
+	defer s.observe("ListByMonth")(&err)
+	return s.next.ListByMonth(ctx, t)
+}
+
+func (s *EventStorage) ListByRange(ctx context.Context, from, to time.Time) (events []event.Event, err error) {
+	defer s.observe("ListByRange")(&err)
+	return s.next.ListByRange(ctx, from, to)
+}
+
+func (s *EventStorage) ListAllByRange(ctx context.Context, from, to time.Time) (events []event.Event, err error) {
+	defer s.observe("ListAllByRange")(&err)
+	return s.next.ListAllByRange(ctx, from, to)
+}
+
+func (s *EventStorage) List(ctx context.Context) (events []event.Event, err error) {
+	defer s.observe("List")(&err)
+	return s.next.List(ctx)
+}
+
+func (s *EventStorage) Count(ctx context.Context) (n int, err error) {
+	defer s.observe("Count")(&err)
+	return s.next.Count(ctx)
+}
+
+func (s *EventStorage) Purge(ctx context.Context) (events []event.Event, err error) {
+	defer s.observe("Purge")(&err)
+	return s.next.Purge(ctx)
+}
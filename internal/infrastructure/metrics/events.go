@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"calendar/internal/event"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Events считает изменения событий по типу. Реализует event.Observer
+type Events struct {
+	changes *prometheus.CounterVec
+}
+
+func NewEvents(reg prometheus.Registerer) *Events {
+	m := &Events{
+		changes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Event changes by type: created, updated, deleted.",
+		}, []string{"type"}),
+	}
+	for _, t := range []event.ChangeType{event.ChangeCreated, event.ChangeUpdated, event.ChangeDeleted} {
+		m.changes.WithLabelValues(string(t))
+	}
+	reg.MustRegister(m.changes)
+	return m
+}
+
+func (m *Events) OnChange(c event.Change) {
+	m.changes.WithLabelValues(string(c.Type)).Inc()
+}
@@ -0,0 +1,73 @@
+// Package file provides хранилища на локальной файловой системе
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ReminderLedger журнал отправленных напоминаний, по ключу на строку.
+// Каждая отметка дописывается в конец файла и сбрасывается на диск
+type ReminderLedger struct {
+	mu   sync.Mutex
+	f    *os.File
+	sent map[string]struct{}
+}
+
+func NewReminderLedger(path string) (*ReminderLedger, error) {
+	const op = "infra.storage.file.reminder_ledger.new"
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sent := make(map[string]struct{})
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if key := sc.Text(); key != "" {
+			sent[key] = struct{}{}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &ReminderLedger{f: f, sent: sent}, nil
+}
+
+func (l *ReminderLedger) Sent(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.sent[key]
+	return ok
+}
+
+func (l *ReminderLedger) MarkSent(key string) (bool, error) {
+	const op = "infra.storage.file.reminder_ledger.mark_sent"
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.sent[key]; ok {
+		return false, nil
+	}
+	if _, err := l.f.WriteString(key + "\n"); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := l.f.Sync(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	l.sent[key] = struct{}{}
+	return true, nil
+}
+
+func (l *ReminderLedger) Close() error {
+	return l.f.Close()
+}
@@ -0,0 +1,109 @@
+package inmem
+
+import (
+	"calendar/internal/acl"
+	"calendar/internal/tenant"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// aclKey календарь в пределах организации
+type aclKey struct {
+	org      uint64
+	calendar uint64
+}
+
+type ACLStorage struct {
+	mu     sync.RWMutex
+	grants map[aclKey]map[uint64]acl.Role
+}
+
+func NewACL() *ACLStorage {
+	return &ACLStorage{grants: make(map[aclKey]map[uint64]acl.Role)}
+}
+
+func (s *ACLStorage) Put(ctx context.Context, g acl.Grant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := aclKey{org: tenant.FromContext(ctx), calendar: g.CalendarUUID}
+	cal, ok := s.grants[key]
+	if !ok {
+		cal = make(map[uint64]acl.Role)
+		s.grants[key] = cal
+	}
+	cal[g.UserUUID] = g.Role
+	return nil
+}
+
+func (s *ACLStorage) Delete(ctx context.Context, calendarUUID, userUUID uint64) error {
+	const op = "infra.storage.in_memory.acl.delete"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := aclKey{org: tenant.FromContext(ctx), calendar: calendarUUID}
+	if _, ok := s.grants[key][userUUID]; !ok {
+		return fmt.Errorf("%s: error: %w, %v/%v", op, acl.ErrNotFound, calendarUUID, userUUID)
+	}
+	delete(s.grants[key], userUUID)
+	return nil
+}
+
+func (s *ACLStorage) List(ctx context.Context, calendarUUID uint64) ([]acl.Grant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key := aclKey{org: tenant.FromContext(ctx), calendar: calendarUUID}
+	res := make([]acl.Grant, 0, len(s.grants[key]))
+	for user, role := range s.grants[key] {
+		res = append(res, acl.Grant{CalendarUUID: calendarUUID, UserUUID: user, Role: role})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].UserUUID < res[j].UserUUID })
+	return res, nil
+}
+
+func (s *ACLStorage) Claim(ctx context.Context, g acl.Grant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := aclKey{org: tenant.FromContext(ctx), calendar: g.CalendarUUID}
+	if len(s.grants[key]) > 0 {
+		return nil
+	}
+	s.grants[key] = map[uint64]acl.Role{g.UserUUID: g.Role}
+	return nil
+}
+
+func (s *ACLStorage) ListAll(ctx context.Context) ([]acl.Grant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	org := tenant.FromContext(ctx)
+	res := []acl.Grant{}
+	for key, cal := range s.grants {
+		if key.org != org {
+			continue
+		}
+		for user, role := range cal {
+			res = append(res, acl.Grant{CalendarUUID: key.calendar, UserUUID: user, Role: role})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].CalendarUUID != res[j].CalendarUUID {
+			return res[i].CalendarUUID < res[j].CalendarUUID
+		}
+		return res[i].UserUUID < res[j].UserUUID
+	})
+	return res, nil
+}
+
+func (s *ACLStorage) Purge(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org := tenant.FromContext(ctx)
+	n := 0
+	for key, cal := range s.grants {
+		if key.org == org {
+			n += len(cal)
+			delete(s.grants, key)
+		}
+	}
+	return n, nil
+}
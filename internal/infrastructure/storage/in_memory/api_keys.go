@@ -0,0 +1,110 @@
+package inmem
+
+import (
+	"calendar/internal/apikey"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type APIKeyStorage struct {
+	mu       sync.RWMutex
+	keys     map[uint64]apikey.Key
+	byPrefix map[string]uint64
+	lastID   uint64
+}
+
+func NewAPIKeys() *APIKeyStorage {
+	return &APIKeyStorage{
+		keys:     make(map[uint64]apikey.Key),
+		byPrefix: make(map[string]uint64),
+	}
+}
+
+func (s *APIKeyStorage) Add(k apikey.Key) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	k.ID = s.lastID
+	s.keys[k.ID] = k
+	s.byPrefix[k.Prefix] = k.ID
+	return k.ID, nil
+}
+
+func (s *APIKeyStorage) Get(id uint64) (apikey.Key, error) {
+	const op = "infra.storage.in_memory.api_keys.get"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return apikey.Key{}, fmt.Errorf("%s: error: %w, %v", op, apikey.ErrNotFound, id)
+	}
+	return k, nil
+}
+
+func (s *APIKeyStorage) GetByPrefix(prefix string) (apikey.Key, error) {
+	const op = "infra.storage.in_memory.api_keys.get_by_prefix"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.byPrefix[prefix]
+	if !ok {
+		return apikey.Key{}, fmt.Errorf("%s: error: %w, %v", op, apikey.ErrNotFound, prefix)
+	}
+	return s.keys[id], nil
+}
+
+// Update заменяет ключ, при ротации старый префикс перестает находиться
+func (s *APIKeyStorage) Update(k apikey.Key) error {
+	const op = "infra.storage.in_memory.api_keys.update"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.keys[k.ID]
+	if !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, apikey.ErrNotFound, k.ID)
+	}
+	delete(s.byPrefix, old.Prefix)
+	s.keys[k.ID] = k
+	s.byPrefix[k.Prefix] = k.ID
+	return nil
+}
+
+func (s *APIKeyStorage) List(userUUID uint64) ([]apikey.Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]apikey.Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		if userUUID == 0 || k.UserUUID == userUUID {
+			res = append(res, k)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (s *APIKeyStorage) Touch(id uint64, at time.Time) error {
+	const op = "infra.storage.in_memory.api_keys.touch"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, apikey.ErrNotFound, id)
+	}
+	k.LastUsedAt = at
+	s.keys[id] = k
+	return nil
+}
+
+func (s *APIKeyStorage) Purge(orgUUID uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, k := range s.keys {
+		if k.OrgUUID == orgUUID {
+			delete(s.keys, id)
+			delete(s.byPrefix, k.Prefix)
+			n++
+		}
+	}
+	return n, nil
+}
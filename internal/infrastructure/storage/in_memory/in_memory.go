@@ -3,16 +3,15 @@ package inmem
 
 import (
 	"calendar/internal/event"
-	"errors"
+	"calendar/internal/tenant"
+	"context"
 	"fmt"
 	"sync"
 	"time"
 )
 
-var (
-	ErrNoValue = errors.New("no value")
-)
-
+// Storage события всех организаций в одной карте, UUID сквозные. Каждый
+// метод видит только события организации из ctx
 type Storage struct {
 	mu     sync.Mutex
 	db     map[uint64]event.Event
@@ -24,88 +23,171 @@ func New() *Storage {
 	return &Storage{db: db}
 }
 
-func (s *Storage) Add(e event.Event) error {
+func (s *Storage) Add(ctx context.Context, e event.Event) (uint64, error) {
 	const op = "infra.storage.in_memory.save"
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if e.UUID == 0 {
 		s.lastID++
 		e.UUID = s.lastID
+	} else if _, ok := s.db[e.UUID]; ok {
+		return 0, fmt.Errorf("%s: error: %w, %v", op, event.ErrConflict, e.UUID)
 	}
+	e.OrgUUID = tenant.FromContext(ctx)
 	s.db[e.UUID] = e
 
-	return nil
+	return e.UUID, nil
+}
+
+func (s *Storage) Get(ctx context.Context, id uint64) (event.Event, error) {
+	const op = "infra.storage.in_memory.get"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(ctx, id)
+	if !ok {
+		return event.Event{}, fmt.Errorf("%s: error: %w, %v", op, event.ErrNotFound, id)
+	}
+	return e, nil
 }
 
-func (s *Storage) Update(e event.Event) error {
+func (s *Storage) Update(ctx context.Context, e event.Event) error {
 	const op = "infra.storage.in_memory.update"
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.db[e.UUID]; !ok {
-		return fmt.Errorf("%s: error: %w, %v", op, ErrNoValue, e.UUID)
-	} else {
-		s.db[e.UUID] = e
+	if _, ok := s.lookup(ctx, e.UUID); !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, event.ErrNotFound, e.UUID)
 	}
+	e.OrgUUID = tenant.FromContext(ctx)
+	s.db[e.UUID] = e
 	return nil
 }
 
-func (s *Storage) Delete(id uint64) error {
+func (s *Storage) Delete(ctx context.Context, id uint64) error {
 	const op = "infra.storage.in_memory.delete"
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.db[id]; !ok {
-		return fmt.Errorf("%s: error: %w, %v", op, ErrNoValue, id)
+	if _, ok := s.lookup(ctx, id); !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, event.ErrNotFound, id)
 	}
 
 	delete(s.db, id)
 	return nil
 }
 
-func (s *Storage) ListByDay(t time.Time) ([]event.Event, error) {
+func (s *Storage) ListByDay(ctx context.Context, t time.Time) ([]event.Event, error) {
 	const op = "infra.storage.in_memory.list_by_day"
-	result := []event.Event{}
-	if len(s.db) == 0 {
-		return nil, fmt.Errorf("%s: error: %w", op, ErrNoValue)
-	}
 	y, m, d := t.Date()
-	for _, event := range s.db {
-		y1, m1, d1 := event.Date.Date()
-		if y == y1 && m == m1 && d == d1 {
-			result = append(result, event)
+	return s.filter(ctx, op, func(e event.Event) bool {
+		y1, m1, d1 := e.Date.Date()
+		return y == y1 && m == m1 && d == d1
+	})
+}
+
+func (s *Storage) ListByWeek(ctx context.Context, t time.Time) ([]event.Event, error) {
+	const op = "infra.storage.in_memory.list_by_week"
+	y, w := t.ISOWeek()
+	return s.filter(ctx, op, func(e event.Event) bool {
+		y1, w1 := e.Date.ISOWeek()
+		return y == y1 && w == w1
+	})
+}
+
+func (s *Storage) ListByMonth(ctx context.Context, t time.Time) ([]event.Event, error) {
+	const op = "infra.storage.in_memory.list_by_month"
+	y, m, _ := t.Date()
+	return s.filter(ctx, op, func(e event.Event) bool {
+		y1, m1, _ := e.Date.Date()
+		return y == y1 && m == m1
+	})
+}
+
+// ListByRange возвращает события, пересекающиеся с [from, to)
+func (s *Storage) ListByRange(ctx context.Context, from, to time.Time) ([]event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.collect(ctx, func(e event.Event) bool {
+		return e.Date.Before(to) && e.EndTime().After(from)
+	}), nil
+}
+
+func (s *Storage) ListAllByRange(ctx context.Context, from, to time.Time) ([]event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := []event.Event{}
+	for _, e := range s.db {
+		if e.Date.Before(to) && e.EndTime().After(from) {
+			result = append(result, e)
 		}
 	}
 	return result, nil
 }
 
-func (s *Storage) ListByWeek(t time.Time) ([]event.Event, error) {
-	const op = "infra.storage.in_memory.list_by_week"
-	result := []event.Event{}
-	if len(s.db) == 0 {
-		return nil, fmt.Errorf("%s: error: %w", op, ErrNoValue)
+func (s *Storage) List(ctx context.Context) ([]event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.collect(ctx, func(event.Event) bool { return true }), nil
+}
+
+func (s *Storage) Count(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org := tenant.FromContext(ctx)
+	n := 0
+	for _, e := range s.db {
+		if e.OrgUUID == org {
+			n++
+		}
 	}
-	y, w := t.ISOWeek()
-	for _, event := range s.db {
-		y1, w1 := event.Date.ISOWeek()
-		if y == y1 && w == w1 {
-			result = append(result, event)
+	return n, nil
+}
+
+func (s *Storage) Purge(ctx context.Context) ([]event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := s.collect(ctx, func(event.Event) bool { return true })
+	for _, e := range deleted {
+		delete(s.db, e.UUID)
+	}
+	return deleted, nil
+}
+
+// lookup событие организации из ctx, вызывается под s.mu
+func (s *Storage) lookup(ctx context.Context, id uint64) (event.Event, bool) {
+	e, ok := s.db[id]
+	if !ok || e.OrgUUID != tenant.FromContext(ctx) {
+		return event.Event{}, false
+	}
+	return e, true
+}
+
+// filter для списков по календарным периодам: пустая организация, как и
+// раньше пустое хранилище, дает ErrNotFound
+func (s *Storage) filter(ctx context.Context, op string, match func(event.Event) bool) ([]event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org := tenant.FromContext(ctx)
+	empty := true
+	for _, e := range s.db {
+		if e.OrgUUID == org {
+			empty = false
+			break
 		}
 	}
-	return result, nil
+	if empty {
+		return nil, fmt.Errorf("%s: error: %w", op, event.ErrNotFound)
+	}
+	return s.collect(ctx, match), nil
 }
 
-func (s *Storage) ListByMonth(t time.Time) ([]event.Event, error) {
-	const op = "infra.storage.in_memory.list_by_month"
+// collect вызывается под s.mu
+func (s *Storage) collect(ctx context.Context, match func(event.Event) bool) []event.Event {
+	org := tenant.FromContext(ctx)
 	result := []event.Event{}
-	if len(s.db) == 0 {
-		return nil, fmt.Errorf("%s: error: %w", op, ErrNoValue)
-	}
-	y, m, _ := t.Date()
-	for _, event := range s.db {
-		y1, m1, _ := event.Date.Date()
-		if y == y1 && m == m1 {
-			result = append(result, event)
+	for _, e := range s.db {
+		if e.OrgUUID == org && match(e) {
+			result = append(result, e)
 		}
 	}
-	return result, nil
+	return result
 }
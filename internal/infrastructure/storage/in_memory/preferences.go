@@ -0,0 +1,115 @@
+package inmem
+
+import (
+	"calendar/internal/preferences"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// profileKey профиль пользователя в пределах организации
+type profileKey struct {
+	org  uint64
+	user uint64
+}
+
+type PreferencesStorage struct {
+	mu sync.RWMutex
+	db map[profileKey]preferences.Profile
+}
+
+func NewPreferences() *PreferencesStorage {
+	return &PreferencesStorage{db: make(map[profileKey]preferences.Profile)}
+}
+
+func (s *PreferencesStorage) Add(p preferences.Profile) error {
+	const op = "infra.storage.in_memory.preferences.save"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := profileKey{org: p.OrgUUID, user: p.UserUUID}
+	if _, ok := s.db[k]; ok {
+		return fmt.Errorf("%s: error: %w, %v", op, preferences.ErrExists, p.UserUUID)
+	}
+	s.db[k] = p
+	return nil
+}
+
+func (s *PreferencesStorage) Update(p preferences.Profile) error {
+	const op = "infra.storage.in_memory.preferences.update"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := profileKey{org: p.OrgUUID, user: p.UserUUID}
+	if _, ok := s.db[k]; !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, preferences.ErrNotFound, p.UserUUID)
+	}
+	s.db[k] = p
+	return nil
+}
+
+func (s *PreferencesStorage) Delete(orgUUID, userUUID uint64) error {
+	const op = "infra.storage.in_memory.preferences.delete"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := profileKey{org: orgUUID, user: userUUID}
+	if _, ok := s.db[k]; !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, preferences.ErrNotFound, userUUID)
+	}
+	delete(s.db, k)
+	return nil
+}
+
+func (s *PreferencesStorage) Get(orgUUID, userUUID uint64) (preferences.Profile, error) {
+	const op = "infra.storage.in_memory.preferences.get"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.db[profileKey{org: orgUUID, user: userUUID}]
+	if !ok {
+		return preferences.Profile{}, fmt.Errorf("%s: error: %w, %v", op, preferences.ErrNotFound, userUUID)
+	}
+	return p, nil
+}
+
+func (s *PreferencesStorage) GetMany(orgUUID uint64, userUUIDs []uint64) (map[uint64]preferences.Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make(map[uint64]preferences.Profile, len(userUUIDs))
+	for _, id := range userUUIDs {
+		if p, ok := s.db[profileKey{org: orgUUID, user: id}]; ok {
+			res[id] = p
+		}
+	}
+	return res, nil
+}
+
+func (s *PreferencesStorage) List(orgUUID uint64) ([]preferences.Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := []preferences.Profile{}
+	for k, p := range s.db {
+		if k.org == orgUUID {
+			res = append(res, p)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].UserUUID < res[j].UserUUID })
+	return res, nil
+}
+
+func (s *PreferencesStorage) Purge(orgUUID uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for k := range s.db {
+		if k.org == orgUUID {
+			delete(s.db, k)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+package inmem
+
+import (
+	"calendar/internal/ratelimit"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter корзины в памяти процесса. Корзины, к которым не обращались
+// дольше idleTTL, удаляются при очередном Allow не чаще раза в idleTTL.
+// idleTTL не меньше времени наполнения корзины делает удаление незаметным
+// для клиента
+type RateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	idleTTL   time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewRateLimiter(idleTTL time.Duration) *RateLimiter {
+	return &RateLimiter{
+		buckets:   make(map[string]*bucket),
+		idleTTL:   idleTTL,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (s *RateLimiter) Allow(key string, l ratelimit.Limit) ratelimit.Decision {
+	if l.Unlimited() {
+		return ratelimit.Decision{Allowed: true}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(l.Capacity())
+	rate := l.Rate()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	d := ratelimit.Decision{Limit: l.Capacity()}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = seconds((capacity - b.tokens) / rate)
+	return d
+}
+
+func (s *RateLimiter) sweep(now time.Time) {
+	if s.idleTTL <= 0 || now.Sub(s.lastSweep) < s.idleTTL {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= s.idleTTL {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
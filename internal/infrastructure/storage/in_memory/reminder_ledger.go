@@ -0,0 +1,30 @@
+package inmem
+
+import "sync"
+
+// ReminderLedger журнал отправленных напоминаний в памяти, не переживает перезапуск
+type ReminderLedger struct {
+	mu   sync.Mutex
+	sent map[string]struct{}
+}
+
+func NewReminderLedger() *ReminderLedger {
+	return &ReminderLedger{sent: make(map[string]struct{})}
+}
+
+func (l *ReminderLedger) Sent(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.sent[key]
+	return ok
+}
+
+func (l *ReminderLedger) MarkSent(key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.sent[key]; ok {
+		return false, nil
+	}
+	l.sent[key] = struct{}{}
+	return true, nil
+}
@@ -0,0 +1,94 @@
+package inmem
+
+import (
+	"calendar/internal/sharelink"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type ShareLinkStorage struct {
+	mu     sync.RWMutex
+	links  map[uint64]sharelink.Link
+	byHash map[string]uint64
+	lastID uint64
+}
+
+func NewShareLinks() *ShareLinkStorage {
+	return &ShareLinkStorage{
+		links:  make(map[uint64]sharelink.Link),
+		byHash: make(map[string]uint64),
+	}
+}
+
+func (s *ShareLinkStorage) Add(l sharelink.Link) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	l.ID = s.lastID
+	s.links[l.ID] = l
+	s.byHash[hex.EncodeToString(l.Hash)] = l.ID
+	return l.ID, nil
+}
+
+func (s *ShareLinkStorage) Get(id uint64) (sharelink.Link, error) {
+	const op = "infra.storage.in_memory.share_links.get"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	l, ok := s.links[id]
+	if !ok {
+		return sharelink.Link{}, fmt.Errorf("%s: error: %w, %v", op, sharelink.ErrNotFound, id)
+	}
+	return l, nil
+}
+
+// GetByHash в ошибку хеш не попадает, по нему находится ссылка
+func (s *ShareLinkStorage) GetByHash(hash []byte) (sharelink.Link, error) {
+	const op = "infra.storage.in_memory.share_links.get_by_hash"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.byHash[hex.EncodeToString(hash)]
+	if !ok {
+		return sharelink.Link{}, fmt.Errorf("%s: error: %w", op, sharelink.ErrNotFound)
+	}
+	return s.links[id], nil
+}
+
+func (s *ShareLinkStorage) Update(l sharelink.Link) error {
+	const op = "infra.storage.in_memory.share_links.update"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.links[l.ID]; !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, sharelink.ErrNotFound, l.ID)
+	}
+	s.links[l.ID] = l
+	return nil
+}
+
+func (s *ShareLinkStorage) List(orgUUID, calendarUUID uint64) ([]sharelink.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]sharelink.Link, 0)
+	for _, l := range s.links {
+		if l.OrgUUID == orgUUID && (calendarUUID == 0 || l.CalendarUUID == calendarUUID) {
+			res = append(res, l)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (s *ShareLinkStorage) Purge(orgUUID uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, l := range s.links {
+		if l.OrgUUID == orgUUID {
+			delete(s.byHash, hex.EncodeToString(l.Hash))
+			delete(s.links, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,137 @@
+package inmem
+
+import (
+	"calendar/internal/user"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type UserStorage struct {
+	mu       sync.RWMutex
+	users    map[uint64]user.User
+	byEmail  map[string]uint64
+	sessions map[string]user.Session
+	lastID   uint64
+}
+
+func NewUsers() *UserStorage {
+	return &UserStorage{
+		users:    make(map[uint64]user.User),
+		byEmail:  make(map[string]uint64),
+		sessions: make(map[string]user.Session),
+	}
+}
+
+func (s *UserStorage) Add(u user.User) (uint64, error) {
+	const op = "infra.storage.in_memory.users.add"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byEmail[u.Email]; ok {
+		return 0, fmt.Errorf("%s: error: %w, %v", op, user.ErrExists, u.Email)
+	}
+	s.lastID++
+	u.UUID = s.lastID
+	s.users[u.UUID] = u
+	s.byEmail[u.Email] = u.UUID
+	return u.UUID, nil
+}
+
+func (s *UserStorage) Get(id uint64) (user.User, error) {
+	const op = "infra.storage.in_memory.users.get"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[id]
+	if !ok {
+		return user.User{}, fmt.Errorf("%s: error: %w, %v", op, user.ErrNotFound, id)
+	}
+	return u, nil
+}
+
+func (s *UserStorage) GetByEmail(email string) (user.User, error) {
+	const op = "infra.storage.in_memory.users.get_by_email"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.byEmail[email]
+	if !ok {
+		return user.User{}, fmt.Errorf("%s: error: %w, %v", op, user.ErrNotFound, email)
+	}
+	return s.users[id], nil
+}
+
+func (s *UserStorage) List(orgUUID uint64) ([]user.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := []user.User{}
+	for _, u := range s.users {
+		if u.OrgUUID == orgUUID {
+			res = append(res, u)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].UUID < res[j].UUID })
+	return res, nil
+}
+
+func (s *UserStorage) Purge(orgUUID uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, u := range s.users {
+		if u.OrgUUID == orgUUID {
+			delete(s.users, id)
+			delete(s.byEmail, u.Email)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Сеансы живут в том же хранилище: они не переживают перезапуск, как и
+// сами учетные записи
+
+func (s *UserStorage) AddSession(sess user.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sess.ID] = sess
+	return nil
+}
+
+func (s *UserStorage) GetSession(id string) (user.Session, error) {
+	const op = "infra.storage.in_memory.users.get_session"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return user.Session{}, fmt.Errorf("%s: error: %w, %v", op, user.ErrSessionNotFound, id)
+	}
+	return sess, nil
+}
+
+func (s *UserStorage) RevokeSession(id string, at time.Time) error {
+	const op = "infra.storage.in_memory.users.revoke_session"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return fmt.Errorf("%s: error: %w, %v", op, user.ErrSessionNotFound, id)
+	}
+	if sess.RevokedAt.IsZero() {
+		sess.RevokedAt = at
+		s.sessions[id] = sess
+	}
+	return nil
+}
+
+func (s *UserStorage) PurgeSessions(orgUUID uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, sess := range s.sessions {
+		if sess.OrgUUID == orgUUID {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,97 @@
+package inmem
+
+import (
+	"calendar/internal/webhook"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// maxDeadLetters ограничивает dead-letter список, старые записи вытесняются
+const maxDeadLetters = 1000
+
+type WebhookStorage struct {
+	mu          sync.RWMutex
+	subs        map[uint64]webhook.Subscription
+	lastID      uint64
+	deadLetters []webhook.DeadLetter
+}
+
+func NewWebhooks() *WebhookStorage {
+	return &WebhookStorage{subs: make(map[uint64]webhook.Subscription)}
+}
+
+func (s *WebhookStorage) Add(sub webhook.Subscription) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	sub.ID = s.lastID
+	s.subs[sub.ID] = sub
+	return sub.ID, nil
+}
+
+func (s *WebhookStorage) Delete(orgUUID, id uint64) error {
+	const op = "infra.storage.in_memory.webhooks.delete"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub, ok := s.subs[id]; !ok || sub.OrgUUID != orgUUID {
+		return fmt.Errorf("%s: error: %w, %v", op, webhook.ErrNotFound, id)
+	}
+	delete(s.subs, id)
+	return nil
+}
+
+func (s *WebhookStorage) List(orgUUID uint64) ([]webhook.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]webhook.Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		if sub.OrgUUID == orgUUID {
+			res = append(res, sub)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (s *WebhookStorage) AddDeadLetter(d webhook.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.deadLetters) >= maxDeadLetters {
+		s.deadLetters = s.deadLetters[1:]
+	}
+	s.deadLetters = append(s.deadLetters, d)
+	return nil
+}
+
+func (s *WebhookStorage) ListDeadLetters(orgUUID uint64) ([]webhook.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]webhook.DeadLetter, 0, len(s.deadLetters))
+	for _, d := range s.deadLetters {
+		if d.OrgUUID == orgUUID {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+func (s *WebhookStorage) Purge(orgUUID uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, sub := range s.subs {
+		if sub.OrgUUID == orgUUID {
+			delete(s.subs, id)
+			n++
+		}
+	}
+	kept := s.deadLetters[:0]
+	for _, d := range s.deadLetters {
+		if d.OrgUUID != orgUUID {
+			kept = append(kept, d)
+		}
+	}
+	s.deadLetters = kept
+	return n, nil
+}
@@ -0,0 +1,144 @@
+// Package lifecycle provides запуск и упорядоченную остановку частей
+// процесса: серверов, фоновых воркеров и хранилищ
+package lifecycle
+
+import (
+	"calendar/pkg/sl_logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+var ErrNotRunning = errors.New("component is not running")
+
+// Component часть процесса. Start блокируется, пока компонент работает,
+// его ошибка останавливает весь процесс. Stop завершает компонент в
+// пределах ctx. Любое из полей может быть nil
+type Component struct {
+	Name  string
+	Start func() error
+	Stop  func(ctx context.Context) error
+}
+
+// Worker компонент из функции, работающей до отмены ctx, как
+// reminder.Scheduler.Run. Stop отменяет ctx и ждет возврата run
+func Worker(name string, run func(ctx context.Context)) Component {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	return Component{
+		Name: name,
+		Start: func() error {
+			defer close(done)
+			run(ctx)
+			return nil
+		},
+		Stop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	}
+}
+
+// Closer компонент без запуска, при остановке закрывает c. Подходит для
+// хранилищ, которым нужно сбросить данные на диск
+func Closer(name string, c io.Closer) Component {
+	return Component{
+		Name: name,
+		Stop: func(context.Context) error { return c.Close() },
+	}
+}
+
+// Manager запускает компоненты в порядке добавления и останавливает в
+// обратном: сначала серверы перестают принимать запросы, затем воркеры
+// дорабатывают порожденные ими изменения, последними закрываются хранилища
+type Manager struct {
+	log        *slog.Logger
+	components []Component
+	// running компоненты с Start, true пока Start не вернулся
+	running map[string]*atomic.Bool
+}
+
+func New(log *slog.Logger) *Manager {
+	return &Manager{
+		log:     log.With(slog.String("component", "lifecycle")),
+		running: make(map[string]*atomic.Bool),
+	}
+}
+
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+	if c.Start != nil {
+		m.running[c.Name] = &atomic.Bool{}
+	}
+}
+
+// Running возвращает ErrNotRunning, если компонент еще не запущен или
+// его Start уже вернулся. Используется проверками готовности
+func (m *Manager) Running(name string) error {
+	if r, ok := m.running[name]; ok && r.Load() {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", name, ErrNotRunning)
+}
+
+// Run запускает компоненты и ждет отмены ctx или ошибки одного из них,
+// затем останавливает все. На остановку отводится timeout, компоненты,
+// не успевшие за него, получают отмененный ctx в Stop
+func (m *Manager) Run(ctx context.Context, timeout time.Duration) error {
+	errc := make(chan error, len(m.components))
+	for _, c := range m.components {
+		if c.Start == nil {
+			continue
+		}
+		running := m.running[c.Name]
+		running.Store(true)
+		go func() {
+			defer running.Store(false)
+			if err := c.Start(); err != nil {
+				errc <- fmt.Errorf("%s: %w", c.Name, err)
+			}
+		}()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		m.log.Info("shutdown requested")
+	case err = <-errc:
+		m.log.Error("component failed, shutting down", sl.Err(err))
+	}
+	return errors.Join(err, m.shutdown(timeout))
+}
+
+func (m *Manager) shutdown(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(m.components) - 1; i >= 0; i-- {
+		c := m.components[i]
+		if c.Stop == nil {
+			continue
+		}
+		started := time.Now()
+		if err := c.Stop(ctx); err != nil {
+			m.log.Error("failed to stop component", slog.String("name", c.Name), sl.Err(err))
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+			continue
+		}
+		m.log.Info("component stopped",
+			slog.String("name", c.Name),
+			slog.Duration("took", time.Since(started)),
+		)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package lifecycle
+
+import (
+	"calendar/pkg/sl_logger/slog_discard"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunWaitsForInFlightRequest(t *testing.T) {
+	started := make(chan struct{})
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		io.WriteString(w, "done")
+	}))
+	url := "http://" + ts.Listener.Addr().String()
+
+	m := New(slogdiscard.NewDiscardLogger())
+	m.Add(Component{
+		Name: "http server",
+		Start: func() error {
+			if err := ts.Config.Serve(ts.Listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: ts.Config.Shutdown,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- m.Run(ctx, 5*time.Second) }()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	res := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			res <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		res <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the handler")
+	}
+	cancel()
+
+	r := <-res
+	if r.err != nil {
+		t.Fatalf("in-flight request failed: %v", r.err)
+	}
+	if r.status != http.StatusOK || r.body != "done" {
+		t.Fatalf("got %d %q, want 200 \"done\"", r.status, r.body)
+	}
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+
+	if _, err := http.Get(url); err == nil {
+		t.Fatal("server still accepts connections after shutdown")
+	}
+	if err := m.Running("http server"); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Running after shutdown: %v, want ErrNotRunning", err)
+	}
+}
+
+func TestRunStopsInReverseOrder(t *testing.T) {
+	var mu sync.Mutex
+	var stopped []string
+	record := func(name string) Component {
+		return Component{
+			Name: name,
+			Stop: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				stopped = append(stopped, name)
+				return nil
+			},
+		}
+	}
+
+	m := New(slogdiscard.NewDiscardLogger())
+	m.Add(record("storage"))
+	m.Add(Worker("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = append(stopped, "worker")
+	}))
+	m.Add(record("server"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Run(ctx, time.Second); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := []string{"server", "worker", "storage"}
+	if !slices.Equal(stopped, want) {
+		t.Fatalf("stop order %v, want %v", stopped, want)
+	}
+}
+
+func TestRunStopsOnComponentError(t *testing.T) {
+	boom := errors.New("boom")
+	m := New(slogdiscard.NewDiscardLogger())
+	m.Add(Component{Name: "failing", Start: func() error { return boom }})
+
+	err := m.Run(context.Background(), time.Second)
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run: %v, want %v", err, boom)
+	}
+}
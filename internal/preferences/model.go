@@ -0,0 +1,95 @@
+package preferences
+
+import (
+	"calendar/internal/event"
+	"time"
+)
+
+// DayHours рабочее время в один день недели, Start и End смещения от полуночи
+type DayHours struct {
+	Weekday time.Weekday
+	Start   time.Duration
+	End     time.Duration
+}
+
+// OutOfOffice период отсутствия, в который пользователь недоступен целиком
+type OutOfOffice struct {
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+// Profile профиль доступности пользователя
+type Profile struct {
+	UserUUID uint64
+	// OrgUUID организация пользователя, профили разных организаций не
+	// пересекаются
+	OrgUUID      uint64
+	TimeZone     string
+	WorkingHours []DayHours
+	OutOfOffice  []OutOfOffice
+}
+
+// Location часовой пояс профиля, UTC если не задан или некорректен
+func (p Profile) Location() *time.Location {
+	if p.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Windows рабочие интервалы внутри [from, to) за вычетом периодов отсутствия
+func (p Profile) Windows(from, to time.Time) []event.Interval {
+	loc := p.Location()
+	byDay := make(map[time.Weekday][]DayHours, len(p.WorkingHours))
+	for _, h := range p.WorkingHours {
+		byDay[h.Weekday] = append(byDay[h.Weekday], h)
+	}
+
+	windows := []event.Interval{}
+	y, m, d := from.In(loc).Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, h := range byDay[day.Weekday()] {
+			iv := event.Interval{Start: atOffset(day, h.Start), End: atOffset(day, h.End)}
+			if iv.Start.Before(from) {
+				iv.Start = from
+			}
+			if iv.End.After(to) {
+				iv.End = to
+			}
+			if iv.Start.Before(iv.End) {
+				windows = append(windows, iv)
+			}
+		}
+	}
+	windows = event.MergeIntervals(windows)
+
+	if len(p.OutOfOffice) == 0 {
+		return windows
+	}
+	away := make([]event.Interval, 0, len(p.OutOfOffice))
+	for _, o := range p.OutOfOffice {
+		away = append(away, event.Interval{Start: o.Start, End: o.End})
+	}
+	return event.SubtractIntervals(windows, event.MergeIntervals(away))
+}
+
+// IsWorking true если интервал [start, end) целиком попадает в рабочее время
+func (p Profile) IsWorking(start, end time.Time) bool {
+	for _, w := range p.Windows(start, end) {
+		if !w.Start.After(start) && !w.End.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+func atOffset(day time.Time, off time.Duration) time.Time {
+	h := int(off / time.Hour)
+	m := int(off % time.Hour / time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location())
+}
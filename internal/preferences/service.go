@@ -0,0 +1,132 @@
+// Package preferences provides профили доступности пользователей:
+// рабочие часы по дням недели, часовой пояс и периоды отсутствия
+package preferences
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/tenant"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotFound            = errors.New("profile not found")
+	ErrForbidden           = errors.New("profile belongs to another user")
+	ErrExists              = errors.New("profile already exists")
+	ErrInvalidTimeZone     = errors.New("invalid time zone")
+	ErrInvalidWorkingHours = errors.New("working hours end must be after start")
+	ErrInvalidOutOfOffice  = errors.New("out of office end must be after start")
+)
+
+// Service профили организации из ctx, см. tenant.FromContext. Add, Update,
+// Delete и Get работают только с профилем пользователя из ctx, см. Owner.
+// GetMany отдает профили любых пользователей организации для расчетов на
+// стороне сервера: подбора слотов и пометок рабочих часов
+type Service interface {
+	Add(ctx context.Context, p Profile) error
+	Update(ctx context.Context, p Profile) error
+	Delete(ctx context.Context, userUUID uint64) error
+	Get(ctx context.Context, userUUID uint64) (Profile, error)
+	GetMany(ctx context.Context, userUUIDs []uint64) (map[uint64]Profile, error)
+	List(ctx context.Context) ([]Profile, error)
+	Purge(ctx context.Context) (int, error)
+}
+
+type service struct {
+	storage Storage
+}
+
+func NewService(storage Storage) Service {
+	return &service{storage: storage}
+}
+
+// Owner пользователь, чей профиль читается или меняется. С аутентификацией
+// это пользователь из ctx, userUUID может быть пустым или совпадать с ним,
+// иначе ErrForbidden. Без аутентификации возвращает userUUID
+func Owner(ctx context.Context, userUUID uint64) (uint64, error) {
+	const op = "preferences.owner"
+
+	id, ok := auth.FromContext(ctx)
+	switch {
+	case !ok:
+		return userUUID, nil
+	case userUUID != 0 && userUUID != id.UserUUID:
+		return 0, fmt.Errorf("%s: %w: %d", op, ErrForbidden, userUUID)
+	}
+	return id.UserUUID, nil
+}
+
+func (s *service) Add(ctx context.Context, p Profile) error {
+	if err := validate(p); err != nil {
+		return err
+	}
+	var err error
+	if p.UserUUID, err = Owner(ctx, p.UserUUID); err != nil {
+		return err
+	}
+	p.OrgUUID = tenant.FromContext(ctx)
+	return s.storage.Add(p)
+}
+
+func (s *service) Update(ctx context.Context, p Profile) error {
+	if err := validate(p); err != nil {
+		return err
+	}
+	var err error
+	if p.UserUUID, err = Owner(ctx, p.UserUUID); err != nil {
+		return err
+	}
+	p.OrgUUID = tenant.FromContext(ctx)
+	return s.storage.Update(p)
+}
+
+func (s *service) Delete(ctx context.Context, userUUID uint64) error {
+	userUUID, err := Owner(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+	return s.storage.Delete(tenant.FromContext(ctx), userUUID)
+}
+
+func (s *service) Get(ctx context.Context, userUUID uint64) (Profile, error) {
+	userUUID, err := Owner(ctx, userUUID)
+	if err != nil {
+		return Profile{}, err
+	}
+	return s.storage.Get(tenant.FromContext(ctx), userUUID)
+}
+
+func (s *service) GetMany(ctx context.Context, userUUIDs []uint64) (map[uint64]Profile, error) {
+	return s.storage.GetMany(tenant.FromContext(ctx), userUUIDs)
+}
+
+func (s *service) List(ctx context.Context) ([]Profile, error) {
+	return s.storage.List(tenant.FromContext(ctx))
+}
+
+func (s *service) Purge(ctx context.Context) (int, error) {
+	return s.storage.Purge(tenant.FromContext(ctx))
+}
+
+func validate(p Profile) error {
+	const op = "preferences.validate"
+
+	if p.TimeZone != "" {
+		if _, err := time.LoadLocation(p.TimeZone); err != nil {
+			return fmt.Errorf("%s: %w: %q", op, ErrInvalidTimeZone, p.TimeZone)
+		}
+	}
+	for _, h := range p.WorkingHours {
+		if h.End <= h.Start || h.End > 24*time.Hour {
+			return fmt.Errorf("%s: %w: %s", op, ErrInvalidWorkingHours, h.Weekday)
+		}
+	}
+	for _, o := range p.OutOfOffice {
+		if !o.End.After(o.Start) {
+			return fmt.Errorf("%s: %w", op, ErrInvalidOutOfOffice)
+		}
+	}
+	return nil
+}
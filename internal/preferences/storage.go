@@ -0,0 +1,15 @@
+package preferences
+
+// Storage профили ищутся по организации и пользователю, Add и Update берут
+// организацию из Profile.OrgUUID
+type Storage interface {
+	Add(p Profile) error
+	Update(p Profile) error
+	Delete(orgUUID, userUUID uint64) error
+	Get(orgUUID, userUUID uint64) (Profile, error)
+	// GetMany возвращает найденные профили, отсутствующие пропускаются
+	GetMany(orgUUID uint64, userUUIDs []uint64) (map[uint64]Profile, error)
+	List(orgUUID uint64) ([]Profile, error)
+	// Purge удаляет все профили организации и возвращает их число
+	Purge(orgUUID uint64) (int, error)
+}
@@ -0,0 +1,60 @@
+// Package ratelimit provides ограничение частоты запросов клиента по
+// алгоритму token bucket
+package ratelimit
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Чем идентифицируется клиент. Запросы без нужных учетных данных
+// считаются по следующему признаку: ключ, затем пользователь, затем адрес
+const (
+	KeyByAPIKey = "api_key"
+	KeyByUser   = "user"
+	KeyByIP     = "ip"
+)
+
+// Limit корзина емкостью Burst, пополняемая на Requests токенов за Per.
+// Нулевой Requests означает отсутствие ограничения
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// Rate скорость пополнения в токенах в секунду
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Capacity емкость корзины, без Burst равна Requests
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Decision результат попытки взять токен
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter через сколько появится токен, только при отказе
+	RetryAfter time.Duration
+	// Reset через сколько корзина наполнится целиком
+	Reset time.Duration
+}
+
+// Limiter хранит корзины клиентов. key идентифицирует клиента и, для
+// маршрутов со своим лимитом, маршрут
+type Limiter interface {
+	Allow(key string, l Limit) Decision
+}
@@ -0,0 +1,9 @@
+package reminder
+
+// Ledger журнал отправленных напоминаний. Должен переживать перезапуск,
+// чтобы одно и то же напоминание не отправлялось дважды
+type Ledger interface {
+	Sent(key string) bool
+	// MarkSent атомарно отмечает напоминание, false если оно уже было отмечено
+	MarkSent(key string) (bool, error)
+}
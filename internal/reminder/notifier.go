@@ -0,0 +1,42 @@
+package reminder
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Notification данные сработавшего напоминания
+type Notification struct {
+	EventUUID uint64
+	UserUUID  uint64
+	Title     string
+	Start     time.Time
+	Before    time.Duration
+	FireAt    time.Time
+}
+
+// Notifier доставляет напоминания получателю (почта, мессенджер, push)
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier пишет напоминания в лог, используется по умолчанию
+type LogNotifier struct {
+	log *slog.Logger
+}
+
+func NewLogNotifier(log *slog.Logger) *LogNotifier {
+	return &LogNotifier{log: log.With(slog.String("component", "reminder/log_notifier"))}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, r Notification) error {
+	n.log.Info("reminder",
+		slog.Uint64("event", r.EventUUID),
+		slog.Uint64("user", r.UserUUID),
+		slog.String("title", r.Title),
+		slog.Time("start", r.Start),
+		slog.Duration("before", r.Before),
+	)
+	return nil
+}
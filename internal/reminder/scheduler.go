@@ -0,0 +1,196 @@
+// Package reminder provides планировщик напоминаний о событиях
+package reminder
+
+import (
+	"calendar/internal/event"
+	"calendar/pkg/sl_logger/sl"
+	"container/heap"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Scheduler держит напоминания в min-куче и спит до ближайшего из них,
+// не перебирая хранилище событий. Изменения событий получает как event.Observer
+type Scheduler struct {
+	log      *slog.Logger
+	notifier Notifier
+	ledger   Ledger
+
+	mu      sync.Mutex
+	queue   queue
+	byEvent map[uint64][]*item
+	wake    chan struct{}
+}
+
+func NewScheduler(log *slog.Logger, notifier Notifier, ledger Ledger) *Scheduler {
+	return &Scheduler{
+		log:      log.With(slog.String("component", "reminder/scheduler")),
+		notifier: notifier,
+		ledger:   ledger,
+		byEvent:  make(map[uint64][]*item),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Load планирует напоминания уже существующих событий, вызывается при старте
+func (s *Scheduler) Load(events []event.Event) {
+	s.mu.Lock()
+	for _, e := range events {
+		s.schedule(e)
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *Scheduler) OnChange(c event.Change) {
+	s.mu.Lock()
+	switch c.Type {
+	case event.ChangeCreated:
+		s.schedule(c.Event)
+	case event.ChangeUpdated:
+		s.unschedule(c.Event.UUID)
+		s.schedule(c.Event)
+	case event.ChangeDeleted:
+		s.unschedule(c.Event.UUID)
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+// Run обрабатывает напоминания до отмены ctx
+func (s *Scheduler) Run(ctx context.Context) {
+	s.log.Info("reminder scheduler started")
+	defer s.log.Info("reminder scheduler stopped")
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		for _, it := range s.popDue(time.Now()) {
+			s.dispatch(ctx, it)
+		}
+
+		if wait, ok := s.untilNext(time.Now()); ok {
+			timer.Reset(wait)
+		} else {
+			timer.Stop()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// schedule вызывается под s.mu
+func (s *Scheduler) schedule(e event.Event) {
+	now := time.Now()
+	if !e.Date.After(now) {
+		return
+	}
+	for _, r := range e.Reminders {
+		key := reminderKey(e, r)
+		if s.ledger.Sent(key) {
+			continue
+		}
+		it := &item{
+			key: key,
+			at:  e.Date.Add(-r.Before),
+			n: Notification{
+				EventUUID: e.UUID,
+				UserUUID:  e.UserUUID,
+				Title:     e.Title,
+				Start:     e.Date,
+				Before:    r.Before,
+				FireAt:    e.Date.Add(-r.Before),
+			},
+		}
+		heap.Push(&s.queue, it)
+		s.byEvent[e.UUID] = append(s.byEvent[e.UUID], it)
+	}
+}
+
+// unschedule вызывается под s.mu
+func (s *Scheduler) unschedule(id uint64) {
+	for _, it := range s.byEvent[id] {
+		if it.index >= 0 {
+			heap.Remove(&s.queue, it.index)
+		}
+	}
+	delete(s.byEvent, id)
+}
+
+func (s *Scheduler) popDue(now time.Time) []*item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*item
+	for s.queue.Len() > 0 && !s.queue[0].at.After(now) {
+		it := heap.Pop(&s.queue).(*item)
+		due = append(due, it)
+		s.forget(it)
+	}
+	return due
+}
+
+// forget убирает сработавшее напоминание из индекса по событию, вызывается под s.mu
+func (s *Scheduler) forget(it *item) {
+	items := s.byEvent[it.n.EventUUID]
+	for i, other := range items {
+		if other == it {
+			items = append(items[:i], items[i+1:]...)
+			break
+		}
+	}
+	if len(items) == 0 {
+		delete(s.byEvent, it.n.EventUUID)
+		return
+	}
+	s.byEvent[it.n.EventUUID] = items
+}
+
+func (s *Scheduler) untilNext(now time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queue.Len() == 0 {
+		return 0, false
+	}
+	return max(s.queue[0].at.Sub(now), 0), true
+}
+
+// dispatch отмечает напоминание отправленным до вызова Notifier:
+// при сбое между отметкой и доставкой напоминание теряется, но не дублируется
+func (s *Scheduler) dispatch(ctx context.Context, it *item) {
+	first, err := s.ledger.MarkSent(it.key)
+	if err != nil {
+		s.log.Error("failed to mark reminder as sent", slog.String("key", it.key), sl.Err(err))
+		return
+	}
+	if !first {
+		return
+	}
+	if err := s.notifier.Notify(ctx, it.n); err != nil {
+		s.log.Error("failed to send reminder", slog.String("key", it.key), sl.Err(err))
+		return
+	}
+	s.log.Debug("reminder sent", slog.String("key", it.key))
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// reminderKey включает время начала, поэтому перенос события
+// порождает новое напоминание, а не считается уже отправленным
+func reminderKey(e event.Event, r event.Reminder) string {
+	return fmt.Sprintf("%d/%d/%d", e.UUID, e.Date.Unix(), int64(r.Before/time.Second))
+}
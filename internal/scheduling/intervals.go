@@ -0,0 +1,18 @@
+package scheduling
+
+import (
+	"calendar/internal/event"
+	"time"
+)
+
+// widen расширяет занятые интервалы на буфер с обеих сторон
+func widen(busy []event.Interval, buffer time.Duration) []event.Interval {
+	if buffer <= 0 {
+		return busy
+	}
+	res := make([]event.Interval, 0, len(busy))
+	for _, iv := range busy {
+		res = append(res, event.Interval{Start: iv.Start.Add(-buffer), End: iv.End.Add(buffer)})
+	}
+	return event.MergeIntervals(res)
+}
@@ -0,0 +1,83 @@
+package scheduling
+
+import (
+	"calendar/internal/preferences"
+	"time"
+)
+
+// Rank способ упорядочивания найденных слотов
+type Rank string
+
+const (
+	// RankEarliest сначала самые ранние слоты
+	RankEarliest Rank = "earliest"
+	// RankFragmentation сначала слоты, оставляющие меньше неиспользуемых "окон"
+	RankFragmentation Rank = "fragmentation"
+)
+
+const (
+	DefaultStep  = 15 * time.Minute
+	DefaultLimit = 10
+	MaxLimit     = 100
+	// MaxRange наибольший интервал поиска, ограничивает число кандидатов.
+	// То же значение проверяет тег maxduration в dto.SuggestSlotsRequest
+	MaxRange = 31 * 24 * time.Hour
+)
+
+// WorkingHours рабочее время пользователя. Start и End задаются
+// смещением от полуночи в часовом поясе Location
+type WorkingHours struct {
+	Start    time.Duration
+	End      time.Duration
+	Weekdays []time.Weekday
+	Location *time.Location
+}
+
+// DefaultWorkingHours пн-пт с 9:00 до 18:00 UTC
+func DefaultWorkingHours() WorkingHours {
+	return WorkingHours{
+		Start: 9 * time.Hour,
+		End:   18 * time.Hour,
+		Weekdays: []time.Weekday{
+			time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+		},
+		Location: time.UTC,
+	}
+}
+
+// profile представляет рабочие часы как профиль доступности с одинаковым
+// расписанием во все перечисленные дни
+func (wh WorkingHours) profile() preferences.Profile {
+	p := preferences.Profile{WorkingHours: make([]preferences.DayHours, 0, len(wh.Weekdays))}
+	if wh.Location != nil {
+		p.TimeZone = wh.Location.String()
+	}
+	for _, d := range wh.Weekdays {
+		p.WorkingHours = append(p.WorkingHours, preferences.DayHours{Weekday: d, Start: wh.Start, End: wh.End})
+	}
+	return p
+}
+
+type Request struct {
+	Participants []uint64
+	Duration     time.Duration
+	From         time.Time
+	To           time.Time
+	// WorkingHours применяются к участникам без собственных часов в PerUser
+	// и без сохраненного профиля доступности
+	WorkingHours WorkingHours
+	PerUser      map[uint64]WorkingHours
+	// Buffer минимальный зазор между встречами участника и предлагаемым слотом
+	Buffer time.Duration
+	Step   time.Duration
+	Limit  int
+	Rank   Rank
+}
+
+type Slot struct {
+	Start time.Time
+	End   time.Time
+	// Fragmentation суммарная длина остающихся до и после слота окон,
+	// в которые уже не поместится встреча той же длительности
+	Fragmentation time.Duration
+}
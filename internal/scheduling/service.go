@@ -0,0 +1,179 @@
+// Package scheduling provides подбор свободных слотов для встречи
+// нескольких участников поверх event.Service
+package scheduling
+
+import (
+	"calendar/internal/event"
+	"calendar/internal/preferences"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidDuration     = errors.New("duration must be positive")
+	ErrInvalidRange        = errors.New("range end must be after start")
+	ErrRangeTooLong        = fmt.Errorf("range must not exceed %d days", MaxRange/(24*time.Hour))
+	ErrInvalidWorkingHours = errors.New("working hours end must be after start")
+	ErrNoParticipants      = errors.New("no participants")
+)
+
+type Service interface {
+	FindSlots(ctx context.Context, req Request) ([]Slot, error)
+}
+
+type service struct {
+	events   event.Service
+	profiles preferences.Service
+}
+
+// NewService profiles может быть nil, тогда учитываются только рабочие часы из запроса
+func NewService(events event.Service, profiles preferences.Service) Service {
+	return &service{events: events, profiles: profiles}
+}
+
+func (s *service) FindSlots(ctx context.Context, req Request) ([]Slot, error) {
+	const op = "scheduling.find_slots"
+
+	if err := validate(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	step := req.Step
+	if step <= 0 {
+		step = DefaultStep
+	}
+	limit := min(req.Limit, MaxLimit)
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	// занятость запрашиваем с запасом на буфер, чтобы учесть встречи у границ
+	busy, err := s.events.FreeBusy(ctx, req.Participants, req.From.Add(-req.Buffer), req.To.Add(req.Buffer))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var common []event.Interval
+	for i, fb := range busy {
+		p, err := s.availability(ctx, req, fb.UserUUID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		free := event.SubtractIntervals(p.Windows(req.From, req.To), widen(fb.Busy, req.Buffer))
+		if i == 0 {
+			common = free
+		} else {
+			common = event.IntersectIntervals(common, free)
+		}
+		if len(common) == 0 {
+			return []Slot{}, nil
+		}
+	}
+
+	// свободные окна упорядочены по времени, для ранних слотов достаточно
+	// первых limit кандидатов, для фрагментации нужны все
+	enough := limit
+	if req.Rank == RankFragmentation {
+		enough = 0
+	}
+	slots := candidates(common, req.Duration, step, enough)
+	rank(slots, req.Rank)
+	if len(slots) > limit {
+		slots = slots[:limit]
+	}
+	return slots, nil
+}
+
+// availability часы из запроса важнее сохраненного профиля пользователя
+func (s *service) availability(ctx context.Context, req Request, user uint64) (preferences.Profile, error) {
+	if wh, ok := req.PerUser[user]; ok {
+		return wh.profile(), nil
+	}
+	if s.profiles != nil {
+		// профили других участников наружу не отдаются, только слоты
+		ps, err := s.profiles.GetMany(ctx, []uint64{user})
+		if err != nil {
+			return preferences.Profile{}, err
+		}
+		if p, ok := ps[user]; ok {
+			return p, nil
+		}
+	}
+	return req.WorkingHours.profile(), nil
+}
+
+func validate(req Request) error {
+	if len(req.Participants) == 0 {
+		return ErrNoParticipants
+	}
+	if req.Duration <= 0 {
+		return ErrInvalidDuration
+	}
+	if !req.To.After(req.From) {
+		return ErrInvalidRange
+	}
+	if req.To.Sub(req.From) > MaxRange {
+		return ErrRangeTooLong
+	}
+	if req.WorkingHours.End <= req.WorkingHours.Start {
+		return ErrInvalidWorkingHours
+	}
+	for _, wh := range req.PerUser {
+		if wh.End <= wh.Start {
+			return ErrInvalidWorkingHours
+		}
+	}
+	return nil
+}
+
+// candidates перебирает начала слотов по сетке step внутри каждого
+// свободного окна и останавливается на limit слотах, 0 без ограничения
+func candidates(free []event.Interval, d, step time.Duration, limit int) []Slot {
+	res := []Slot{}
+	for _, w := range free {
+		start := w.Start.Truncate(step)
+		if start.Before(w.Start) {
+			start = start.Add(step)
+		}
+		for end := start.Add(d); !end.After(w.End); start, end = start.Add(step), end.Add(step) {
+			res = append(res, Slot{
+				Start:         start,
+				End:           end,
+				Fragmentation: unusable(start.Sub(w.Start), d) + unusable(w.End.Sub(end), d),
+			})
+			if limit > 0 && len(res) == limit {
+				return res
+			}
+		}
+	}
+	return res
+}
+
+// unusable остаток окна считается потерянным, если в него не влезает такая же встреча
+func unusable(gap, d time.Duration) time.Duration {
+	if gap > 0 && gap < d {
+		return gap
+	}
+	return 0
+}
+
+func rank(slots []Slot, r Rank) {
+	switch r {
+	case RankFragmentation:
+		sort.SliceStable(slots, func(i, j int) bool {
+			if slots[i].Fragmentation != slots[j].Fragmentation {
+				return slots[i].Fragmentation < slots[j].Fragmentation
+			}
+			return slots[i].Start.Before(slots[j].Start)
+		})
+	default:
+		sort.SliceStable(slots, func(i, j int) bool {
+			if !slots[i].Start.Equal(slots[j].Start) {
+				return slots[i].Start.Before(slots[j].Start)
+			}
+			return slots[i].Fragmentation < slots[j].Fragmentation
+		})
+	}
+}
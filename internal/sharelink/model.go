@@ -0,0 +1,40 @@
+package sharelink
+
+import "time"
+
+// Link публичная ссылка только на чтение одного календаря. Если задан
+// From или To, по ссылке видны только события, пересекающие это окно.
+// Хранится только SHA-256 токена
+type Link struct {
+	ID           uint64
+	OrgUUID      uint64
+	CalendarUUID uint64
+	From         time.Time
+	To           time.Time
+	Hash         []byte
+	CreatedBy    uint64
+	CreatedAt    time.Time
+	// ExpiresAt нулевое значение означает бессрочную ссылку
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+func (l Link) Revoked() bool {
+	return !l.RevokedAt.IsZero()
+}
+
+func (l Link) Expired(now time.Time) bool {
+	return !l.ExpiresAt.IsZero() && !now.Before(l.ExpiresAt)
+}
+
+// clip сужает запрошенный диапазон до окна ссылки, ok == false если
+// пересечения нет
+func (l Link) clip(from, to time.Time) (time.Time, time.Time, bool) {
+	if !l.From.IsZero() && from.Before(l.From) {
+		from = l.From
+	}
+	if !l.To.IsZero() && to.After(l.To) {
+		to = l.To
+	}
+	return from, to, to.After(from)
+}
@@ -0,0 +1,207 @@
+// Package sharelink provides публичные ссылки на календарь для внешних
+// гостей: неугадываемый токен дает доступ только на чтение без аутентификации
+package sharelink
+
+import (
+	"calendar/internal/acl"
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	"calendar/internal/tenant"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("share link not found")
+	ErrRevoked       = errors.New("share link is revoked")
+	ErrExpired       = errors.New("share link is expired")
+	ErrForbidden     = errors.New("only calendar owner can manage share links")
+	ErrNoCalendar    = errors.New("calendar uuid is required")
+	ErrInvalidRange  = errors.New("share link range end must be after start")
+	ErrInvalidExpiry = errors.New("share link expiry must be in the future")
+)
+
+const (
+	tokenBytes = 32
+
+	// диапазон ленты по умолчанию, если его не задали ни ссылка, ни запрос
+	DefaultPast   = 30 * 24 * time.Hour
+	DefaultFuture = 365 * 24 * time.Hour
+)
+
+// Access роль пользователя в календаре, реализуется acl.Service
+type Access interface {
+	RoleOf(ctx context.Context, calendarUUID, userUUID uint64) (acl.Role, error)
+}
+
+type Service interface {
+	// Create выпускает ссылку и возвращает ее вместе с токеном, который
+	// больше нигде не сохраняется
+	Create(ctx context.Context, l Link) (Link, string, error)
+	List(ctx context.Context, calendarUUID uint64) ([]Link, error)
+	Revoke(ctx context.Context, id uint64) error
+	// Events события календаря ссылки в пересечении [from, to) с ее окном.
+	// Нулевые from и to заменяются диапазоном по умолчанию
+	Events(ctx context.Context, token string, from, to time.Time) (Link, []event.Event, error)
+
+	// Export все ссылки организации из ctx без проверки ролей
+	Export(ctx context.Context) ([]Link, error)
+	Purge(ctx context.Context) (int, error)
+}
+
+type service struct {
+	storage Storage
+	events  event.Service
+	access  Access
+	now     func() time.Time
+}
+
+// NewService access может быть nil, тогда ссылками управляет любой пользователь
+func NewService(storage Storage, events event.Service, access Access) Service {
+	return &service{storage: storage, events: events, access: access, now: time.Now}
+}
+
+func (s *service) Create(ctx context.Context, l Link) (Link, string, error) {
+	const op = "sharelink.create"
+
+	now := s.now()
+	switch {
+	case l.CalendarUUID == 0:
+		return Link{}, "", fmt.Errorf("%s: %w", op, ErrNoCalendar)
+	case !l.From.IsZero() && !l.To.IsZero() && !l.To.After(l.From):
+		return Link{}, "", fmt.Errorf("%s: %w", op, ErrInvalidRange)
+	case l.Expired(now):
+		return Link{}, "", fmt.Errorf("%s: %w", op, ErrInvalidExpiry)
+	}
+	if err := s.requireOwner(ctx, l.CalendarUUID); err != nil {
+		return Link{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return Link{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	l.ID, l.Hash, l.CreatedAt, l.RevokedAt = 0, hash, now, time.Time{}
+	l.OrgUUID = tenant.FromContext(ctx)
+	if id, ok := auth.FromContext(ctx); ok {
+		l.CreatedBy = id.UserUUID
+	}
+
+	if l.ID, err = s.storage.Add(l); err != nil {
+		return Link{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	return l, token, nil
+}
+
+func (s *service) List(ctx context.Context, calendarUUID uint64) ([]Link, error) {
+	const op = "sharelink.list"
+
+	if err := s.requireOwner(ctx, calendarUUID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.storage.List(tenant.FromContext(ctx), calendarUUID)
+}
+
+func (s *service) Revoke(ctx context.Context, id uint64) error {
+	const op = "sharelink.revoke"
+
+	l, err := s.storage.Get(id)
+	if err != nil {
+		return err
+	}
+	// ссылка другой организации для вызывающего не существует
+	if l.OrgUUID != tenant.FromContext(ctx) {
+		return fmt.Errorf("%s: %w: %d", op, ErrNotFound, id)
+	}
+	if err := s.requireOwner(ctx, l.CalendarUUID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if l.Revoked() {
+		return fmt.Errorf("%s: %w: %d", op, ErrRevoked, id)
+	}
+	l.RevokedAt = s.now()
+	return s.storage.Update(l)
+}
+
+func (s *service) Events(ctx context.Context, token string, from, to time.Time) (Link, []event.Event, error) {
+	const op = "sharelink.events"
+
+	sum := sha256.Sum256([]byte(token))
+	l, err := s.storage.GetByHash(sum[:])
+	if err != nil {
+		return Link{}, nil, err
+	}
+	now := s.now()
+	switch {
+	case l.Revoked():
+		return Link{}, nil, fmt.Errorf("%s: %w: %d", op, ErrRevoked, l.ID)
+	case l.Expired(now):
+		return Link{}, nil, fmt.Errorf("%s: %w: %d", op, ErrExpired, l.ID)
+	}
+
+	if from.IsZero() {
+		from = now.Add(-DefaultPast)
+	}
+	if to.IsZero() {
+		to = now.Add(DefaultFuture)
+	}
+	from, to, ok := l.clip(from, to)
+	if !ok {
+		return l, []event.Event{}, nil
+	}
+
+	// гость не аутентифицирован, доступ к календарю дает сама ссылка,
+	// она же определяет организацию
+	all, err := s.events.ListByRange(tenant.WithOrg(ctx, l.OrgUUID), from, to)
+	if err != nil && !errors.Is(err, event.ErrNotFound) {
+		return Link{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	res := make([]event.Event, 0, len(all))
+	for _, e := range all {
+		if e.CalendarUUID == l.CalendarUUID {
+			res = append(res, e)
+		}
+	}
+	return l, res, nil
+}
+
+func (s *service) Export(ctx context.Context) ([]Link, error) {
+	return s.storage.List(tenant.FromContext(ctx), 0)
+}
+
+func (s *service) Purge(ctx context.Context) (int, error) {
+	return s.storage.Purge(tenant.FromContext(ctx))
+}
+
+// requireOwner без аутентификации пропускает всех, как auth.Require
+func (s *service) requireOwner(ctx context.Context, calendarUUID uint64) error {
+	id, ok := auth.FromContext(ctx)
+	if !ok || s.access == nil {
+		return nil
+	}
+	role, err := s.access.RoleOf(ctx, calendarUUID, id.UserUUID)
+	if err != nil {
+		return err
+	}
+	if role < acl.RoleOwner {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// newToken случайный токен для URL и его SHA-256 для хранения. Токен
+// длинный и случайный, поэтому соль не нужна, а поиск по хешу безопасен
+func newToken() (string, []byte, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(token))
+	return token, sum[:], nil
+}
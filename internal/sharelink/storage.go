@@ -0,0 +1,14 @@
+package sharelink
+
+// Storage ссылки ищутся по токену без организации, остальные выборки
+// ограничены организацией явно
+type Storage interface {
+	Add(l Link) (uint64, error)
+	Get(id uint64) (Link, error)
+	GetByHash(hash []byte) (Link, error)
+	Update(l Link) error
+	// List ссылки календаря организации, при calendarUUID == 0 все ее ссылки
+	List(orgUUID, calendarUUID uint64) ([]Link, error)
+	// Purge удаляет все ссылки организации и возвращает их число
+	Purge(orgUUID uint64) (int, error)
+}
@@ -0,0 +1,20 @@
+package tenant
+
+// Quota ограничения одной организации, нулевое значение поля означает
+// отсутствие ограничения
+type Quota struct {
+	MaxEvents int
+}
+
+// Quotas ограничения по умолчанию и переопределения для организаций
+type Quotas struct {
+	Default Quota
+	Orgs    map[uint64]Quota
+}
+
+func (q Quotas) For(orgUUID uint64) Quota {
+	if o, ok := q.Orgs[orgUUID]; ok {
+		return o
+	}
+	return q.Default
+}
@@ -0,0 +1,31 @@
+// Package tenant provides организацию (тенант), в пределах которой
+// выполняется запрос. Данные разных организаций не пересекаются: хранилища
+// берут организацию из контекста в каждом методе
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrQuotaExceeded = errors.New("organization quota exceeded")
+
+// Default организация запросов без org в учетных данных и при выключенной
+// аутентификации
+const Default uint64 = 0
+
+type ctxKey struct{}
+
+// WithOrg сохраняет организацию запроса в контексте. Транспорты делают это
+// сразу после аутентификации
+func WithOrg(ctx context.Context, orgUUID uint64) context.Context {
+	return context.WithValue(ctx, ctxKey{}, orgUUID)
+}
+
+// FromContext возвращает организацию запроса или Default
+func FromContext(ctx context.Context) uint64 {
+	if org, ok := ctx.Value(ctxKey{}).(uint64); ok {
+		return org
+	}
+	return Default
+}
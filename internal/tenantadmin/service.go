@@ -0,0 +1,145 @@
+// Package tenantadmin provides выгрузку и удаление всех данных организации
+// одной операцией
+package tenantadmin
+
+import (
+	"calendar/internal/acl"
+	"calendar/internal/apikey"
+	"calendar/internal/event"
+	"calendar/internal/preferences"
+	"calendar/internal/sharelink"
+	"calendar/internal/tenant"
+	"calendar/internal/user"
+	"calendar/internal/webhook"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Snapshot данные организации на момент выгрузки
+type Snapshot struct {
+	OrgUUID    uint64
+	ExportedAt time.Time
+	Events     []event.Event
+	Grants     []acl.Grant
+	Webhooks   []webhook.Subscription
+	ShareLinks []sharelink.Link
+	Profiles   []preferences.Profile
+	Users      []user.User
+	APIKeys    []apikey.Key
+}
+
+// Deleted число удаленных записей по видам
+type Deleted struct {
+	Events     int
+	Grants     int
+	Webhooks   int
+	ShareLinks int
+	Profiles   int
+	Users      int
+	Sessions   int
+	APIKeys    int
+}
+
+// Service работает с организацией из ctx, см. tenant.FromContext
+type Service interface {
+	Export(ctx context.Context) (Snapshot, error)
+	Delete(ctx context.Context) (Deleted, error)
+}
+
+type service struct {
+	events     event.Service
+	acl        acl.Service
+	webhooks   webhook.Service
+	shareLinks sharelink.Service
+	profiles   preferences.Service
+	users      user.Service
+	apiKeys    apikey.Service
+}
+
+// Services сервисы, данные которых входят в выгрузку и удаление
+type Services struct {
+	Events     event.Service
+	ACL        acl.Service
+	Webhooks   webhook.Service
+	ShareLinks sharelink.Service
+	Profiles   preferences.Service
+	Users      user.Service
+	APIKeys    apikey.Service
+}
+
+func NewService(s Services) Service {
+	return &service{
+		events:     s.Events,
+		acl:        s.ACL,
+		webhooks:   s.Webhooks,
+		shareLinks: s.ShareLinks,
+		profiles:   s.Profiles,
+		users:      s.Users,
+		apiKeys:    s.APIKeys,
+	}
+}
+
+func (s *service) Export(ctx context.Context) (Snapshot, error) {
+	const op = "tenantadmin.export"
+
+	snap := Snapshot{OrgUUID: tenant.FromContext(ctx), ExportedAt: time.Now()}
+	var err error
+	if snap.Events, err = s.events.Export(ctx); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if snap.Grants, err = s.acl.Export(ctx); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if snap.Webhooks, err = s.webhooks.List(ctx); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if snap.ShareLinks, err = s.shareLinks.Export(ctx); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if snap.Profiles, err = s.profiles.List(ctx); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if snap.Users, err = s.users.List(ctx); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// userUUID 0 означает ключи всех пользователей организации
+	if snap.APIKeys, err = s.apiKeys.List(ctx, 0); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return snap, nil
+}
+
+// Delete сначала снимает подписки, чтобы удаление событий не разослало
+// получателям организации уведомления, и отзывает ключи и сеансы, чтобы
+// во время удаления никто не дописал данные. Затем удаляет остальное
+func (s *service) Delete(ctx context.Context) (Deleted, error) {
+	const op = "tenantadmin.delete"
+
+	var res Deleted
+	var err error
+	if res.Webhooks, err = s.webhooks.Purge(ctx); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	if res.APIKeys, err = s.apiKeys.Purge(ctx); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	purged, err := s.users.Purge(ctx)
+	res.Users, res.Sessions = purged.Users, purged.Sessions
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	if res.Events, err = s.events.Purge(ctx); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	if res.Grants, err = s.acl.Purge(ctx); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	if res.ShareLinks, err = s.shareLinks.Purge(ctx); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	if res.Profiles, err = s.profiles.Purge(ctx); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
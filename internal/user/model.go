@@ -0,0 +1,30 @@
+package user
+
+import "time"
+
+// User учетная запись для входа по email и паролю. Пароль хранится только
+// как bcrypt хеш
+type User struct {
+	UUID uint64
+	// OrgUUID организация, в которую пользователь входит после логина
+	OrgUUID      uint64
+	Email        string
+	Name         string
+	PasswordHash []byte
+	CreatedAt    time.Time
+}
+
+// Session сеанс входа. Его ID попадает в claim sid выданного токена,
+// отозванный сеанс делает токен недействительным до истечения его срока
+type Session struct {
+	ID        string
+	UserUUID  uint64
+	OrgUUID   uint64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,215 @@
+// Package user provides учетные записи и вход по паролю, чтобы календарь
+// работал без внешнего провайдера учетных записей
+package user
+
+import (
+	"calendar/internal/tenant"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrNotFound           = errors.New("user not found")
+	ErrExists             = errors.New("user already exists")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionInactive    = errors.New("session is revoked or expired")
+	ErrLoginDisabled      = errors.New("login is disabled: no token signing key configured")
+	ErrInvalidPassword    = errors.New("password must be 8 to 72 bytes long")
+)
+
+const (
+	sessionIDBytes = 16
+
+	// bcrypt учитывает только первые 72 байта пароля
+	minPasswordBytes = 8
+	maxPasswordBytes = 72
+)
+
+// TokenIssuer подписывает токен доступа сеанса. Организация попадает в
+// токен, чтобы запросы по нему шли в организацию пользователя
+type TokenIssuer interface {
+	Issue(userUUID, orgUUID uint64, sessionID string, expiresAt time.Time) (string, error)
+}
+
+// Purged число удаленных записей организации
+type Purged struct {
+	Users    int
+	Sessions int
+}
+
+type Service interface {
+	// Register создает пользователя в организации из ctx
+	Register(ctx context.Context, email, name, password string) (User, error)
+	// Login проверяет пароль, открывает сеанс и возвращает его токен
+	Login(email, password string) (Session, string, error)
+	// Logout отзывает сеанс, выданный по нему токен перестает действовать
+	Logout(sessionID string) error
+	// ValidateSession возвращает ошибку для неизвестного, отозванного или
+	// истекшего сеанса
+	ValidateSession(sessionID string) error
+	// Get пользователь организации из ctx, чужой выглядит как отсутствующий
+	Get(ctx context.Context, uuid uint64) (User, error)
+	List(ctx context.Context) ([]User, error)
+	// Purge удаляет пользователей организации из ctx вместе с их сеансами
+	Purge(ctx context.Context) (Purged, error)
+}
+
+type service struct {
+	users    Storage
+	sessions SessionStorage
+	issuer   TokenIssuer
+	ttl      time.Duration
+	now      func() time.Time
+
+	// dummyHash сравнивается при неизвестном email, чтобы время ответа не
+	// выдавало существование учетной записи
+	dummyHash []byte
+}
+
+// NewService issuer может быть nil, тогда регистрация работает, а вход
+// возвращает ErrLoginDisabled
+func NewService(users Storage, sessions SessionStorage, issuer TokenIssuer, ttl time.Duration) Service {
+	dummy, _ := bcrypt.GenerateFromPassword([]byte("calendar-dummy-password"), bcrypt.DefaultCost)
+	return &service{
+		users:     users,
+		sessions:  sessions,
+		issuer:    issuer,
+		ttl:       ttl,
+		now:       time.Now,
+		dummyHash: dummy,
+	}
+}
+
+func (s *service) Register(ctx context.Context, email, name, password string) (User, error) {
+	const op = "user.register"
+
+	if len(password) < minPasswordBytes || len(password) > maxPasswordBytes {
+		return User{}, fmt.Errorf("%s: %w", op, ErrInvalidPassword)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u := User{
+		OrgUUID:      tenant.FromContext(ctx),
+		Email:        normalizeEmail(email),
+		Name:         name,
+		PasswordHash: hash,
+		CreatedAt:    s.now(),
+	}
+	id, err := s.users.Add(u)
+	if err != nil {
+		return User{}, err
+	}
+	u.UUID = id
+	return u, nil
+}
+
+func (s *service) Login(email, password string) (Session, string, error) {
+	const op = "user.login"
+
+	if s.issuer == nil {
+		return Session{}, "", fmt.Errorf("%s: %w", op, ErrLoginDisabled)
+	}
+
+	u, err := s.users.GetByEmail(normalizeEmail(email))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return Session{}, "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	case err != nil:
+		return Session{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)); err != nil {
+		return Session{}, "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		return Session{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	now := s.now()
+	sess := Session{ID: id, UserUUID: u.UUID, OrgUUID: u.OrgUUID, CreatedAt: now, ExpiresAt: now.Add(s.ttl)}
+	if err := s.sessions.AddSession(sess); err != nil {
+		return Session{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := s.issuer.Issue(u.UUID, u.OrgUUID, sess.ID, sess.ExpiresAt)
+	if err != nil {
+		return Session{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	return sess, token, nil
+}
+
+func (s *service) Logout(sessionID string) error {
+	return s.sessions.RevokeSession(sessionID, s.now())
+}
+
+func (s *service) ValidateSession(sessionID string) error {
+	const op = "user.validate_session"
+
+	sess, err := s.sessions.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if !sess.Active(s.now()) {
+		return fmt.Errorf("%s: %w: %s", op, ErrSessionInactive, sessionID)
+	}
+	return nil
+}
+
+func (s *service) Get(ctx context.Context, uuid uint64) (User, error) {
+	const op = "user.get"
+
+	u, err := s.users.Get(uuid)
+	if err != nil {
+		return User{}, err
+	}
+	if u.OrgUUID != tenant.FromContext(ctx) {
+		return User{}, fmt.Errorf("%s: error: %w, %v", op, ErrNotFound, uuid)
+	}
+	return u, nil
+}
+
+func (s *service) List(ctx context.Context) ([]User, error) {
+	return s.users.List(tenant.FromContext(ctx))
+}
+
+// Purge сначала удаляет сеансы, чтобы выданные токены перестали действовать
+// раньше, чем исчезнут учетные записи
+func (s *service) Purge(ctx context.Context) (Purged, error) {
+	const op = "user.purge"
+
+	org := tenant.FromContext(ctx)
+	var res Purged
+	var err error
+	if res.Sessions, err = s.sessions.PurgeSessions(org); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	if res.Users, err = s.users.Purge(org); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, sessionIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
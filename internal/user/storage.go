@@ -0,0 +1,22 @@
+package user
+
+import "time"
+
+type Storage interface {
+	// Add возвращает ErrExists, если email уже занят в любой организации:
+	// вход по email не знает организацию заранее
+	Add(u User) (uint64, error)
+	Get(uuid uint64) (User, error)
+	GetByEmail(email string) (User, error)
+	List(orgUUID uint64) ([]User, error)
+	// Purge удаляет пользователей организации и возвращает их число
+	Purge(orgUUID uint64) (int, error)
+}
+
+type SessionStorage interface {
+	AddSession(s Session) error
+	GetSession(id string) (Session, error)
+	RevokeSession(id string, at time.Time) error
+	// PurgeSessions удаляет сеансы организации и возвращает их число
+	PurgeSessions(orgUUID uint64) (int, error)
+}
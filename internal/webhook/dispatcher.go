@@ -0,0 +1,331 @@
+package webhook
+
+import (
+	"bytes"
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	"calendar/internal/tenant"
+	"calendar/pkg/sl_logger/sl"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderSignature = "X-Calendar-Signature"
+	HeaderEvent     = "X-Calendar-Event"
+	HeaderDelivery  = "X-Calendar-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	errQueueFull = errors.New("delivery queue is full")
+	errStopped   = errors.New("dispatcher stopped before delivery")
+)
+
+type Options struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Client      *http.Client
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Workers:     4,
+		QueueSize:   1024,
+		MaxAttempts: 5,
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Minute,
+		Client:      Guard{}.Client(10 * time.Second),
+	}
+}
+
+// Viewer проверяет доступ пользователя из ctx к событию, реализуется
+// event.Service
+type Viewer interface {
+	CanView(ctx context.Context, e event.Event) bool
+}
+
+type delivery struct {
+	id      string
+	sub     Subscription
+	typ     event.ChangeType
+	payload []byte
+}
+
+// Dispatcher получает изменения как event.Observer и доставляет их
+// подписчикам в фоне с повторами и экспоненциальной задержкой.
+// Не доставленные за MaxAttempts попыток попадают в dead-letter список
+type Dispatcher struct {
+	log     *slog.Logger
+	storage Storage
+	viewer  Viewer
+	opts    Options
+	queue   chan delivery
+
+	// mu и stopped не дают OnChange положить доставку в очередь, которую
+	// остановленный Run уже не разберет
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// NewDispatcher viewer может быть nil, тогда подписки получают все события
+// своей организации
+func NewDispatcher(log *slog.Logger, storage Storage, viewer Viewer, opts Options) *Dispatcher {
+	def := DefaultOptions()
+	if opts.Workers <= 0 {
+		opts.Workers = def.Workers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = def.QueueSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = def.MaxAttempts
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = def.BaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = def.MaxBackoff
+	}
+	if opts.Client == nil {
+		opts.Client = def.Client
+	}
+	return &Dispatcher{
+		log:     log.With(slog.String("component", "webhook/dispatcher")),
+		storage: storage,
+		viewer:  viewer,
+		opts:    opts,
+		queue:   make(chan delivery, opts.QueueSize),
+	}
+}
+
+// OnChange не блокирует вызывающего: при переполненной очереди или после
+// остановки доставка сразу уходит в dead-letter список. Изменение получают только
+// подписки организации события, владельцы которых видят событие
+func (d *Dispatcher) OnChange(c event.Change) {
+	subs, err := d.storage.List(c.Event.OrgUUID)
+	if err != nil {
+		d.log.Error("failed to list webhooks", sl.Err(err))
+		return
+	}
+
+	for _, sub := range subs {
+		if !sub.Matches(c.Type) || !d.visible(sub, c.Event) {
+			continue
+		}
+		id := uuid.New().String()
+		payload, err := json.Marshal(Payload{
+			ID:         id,
+			Type:       "event." + string(c.Type),
+			OccurredAt: c.At,
+			Event:      c.Event,
+		})
+		if err != nil {
+			d.log.Error("failed to encode webhook payload", sl.Err(err))
+			return
+		}
+
+		d.enqueue(delivery{id: id, sub: sub, typ: c.Type, payload: payload})
+	}
+}
+
+func (d *Dispatcher) enqueue(job delivery) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.stopped {
+		d.deadLetter(job, 0, errStopped)
+		return
+	}
+	select {
+	case d.queue <- job:
+	default:
+		d.deadLetter(job, 0, errQueueFull)
+	}
+}
+
+// visible проверяет событие от имени владельца подписки, как если бы он
+// запросил его через API
+func (d *Dispatcher) visible(sub Subscription, e event.Event) bool {
+	if d.viewer == nil {
+		return true
+	}
+	ctx := tenant.WithOrg(context.Background(), sub.OrgUUID)
+	if sub.UserUUID != 0 {
+		ctx = auth.WithIdentity(ctx, auth.Identity{UserUUID: sub.UserUUID, OrgUUID: sub.OrgUUID})
+	}
+	return d.viewer.CanView(ctx, e)
+}
+
+// Run запускает воркеры доставки и блокируется до отмены ctx. Доставки,
+// оставшиеся в очереди после остановки, переносятся в dead-letter список,
+// откуда их можно отправить повторно
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.log.Info("webhook dispatcher started", slog.Int("workers", d.opts.Workers))
+	defer d.log.Info("webhook dispatcher stopped")
+
+	var wg sync.WaitGroup
+	for range d.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-d.queue:
+					d.deliver(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	d.drain()
+}
+
+func (d *Dispatcher) drain() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	for {
+		select {
+		case job := <-d.queue:
+			d.deadLetter(job, 0, errStopped)
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, job delivery) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = d.send(ctx, job); err == nil {
+			d.log.Debug("webhook delivered",
+				slog.String("delivery", job.id),
+				slog.Uint64("subscription", job.sub.ID),
+				slog.Int("attempt", attempt),
+			)
+			return
+		}
+
+		var perm permanentError
+		if attempt >= d.opts.MaxAttempts || errors.As(err, &perm) {
+			d.deadLetter(job, attempt, err)
+			return
+		}
+
+		d.log.Warn("webhook delivery failed, retrying",
+			slog.String("delivery", job.id),
+			slog.Int("attempt", attempt),
+			sl.Err(err),
+		)
+		select {
+		case <-ctx.Done():
+			d.deadLetter(job, attempt, ctx.Err())
+			return
+		case <-time.After(d.backoff(attempt)):
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, job delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.sub.URL, bytes.NewReader(job.payload))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, "event."+string(job.typ))
+	req.Header.Set(HeaderDelivery, job.id)
+	req.Header.Set(HeaderSignature, Sign(job.sub.Secret, job.payload))
+
+	resp, err := d.opts.Client.Do(req)
+	if errors.Is(err, ErrForbiddenHost) {
+		return permanentError{err}
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	default:
+		return permanentError{fmt.Errorf("unexpected status %d", resp.StatusCode)}
+	}
+}
+
+// backoff BaseBackoff*2^(attempt-1), но не больше MaxBackoff, с джиттером до 20%
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.opts.BaseBackoff << (attempt - 1)
+	if wait <= 0 || wait > d.opts.MaxBackoff {
+		wait = d.opts.MaxBackoff
+	}
+	return wait - time.Duration(rand.Int64N(int64(wait)/5+1))
+}
+
+func (d *Dispatcher) deadLetter(job delivery, attempts int, err error) {
+	d.log.Error("webhook delivery failed permanently",
+		slog.String("delivery", job.id),
+		slog.Uint64("subscription", job.sub.ID),
+		slog.Int("attempts", attempts),
+		sl.Err(err),
+	)
+	dl := DeadLetter{
+		DeliveryID:     job.id,
+		OrgUUID:        job.sub.OrgUUID,
+		SubscriptionID: job.sub.ID,
+		URL:            job.sub.URL,
+		Type:           job.typ,
+		Payload:        job.payload,
+		Attempts:       attempts,
+		LastError:      err.Error(),
+		FailedAt:       time.Now(),
+	}
+	if err := d.storage.AddDeadLetter(dl); err != nil {
+		d.log.Error("failed to store dead letter", sl.Err(err))
+	}
+}
+
+// Sign подпись тела запроса, которую получатель сверяет с заголовком X-Calendar-Signature
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверка подписи на стороне получателя
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// permanentError ошибка, повтор которой не имеет смысла (4xx, некорректный URL)
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+func (e permanentError) Unwrap() error { return e.err }
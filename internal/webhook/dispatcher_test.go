@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"calendar/internal/event"
+	"calendar/pkg/sl_logger/slog_discard"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type memStorage struct {
+	mu          sync.Mutex
+	subs        []Subscription
+	deadLetters []DeadLetter
+}
+
+func (s *memStorage) Add(sub Subscription) (uint64, error) { return 0, nil }
+func (s *memStorage) Delete(orgUUID, id uint64) error      { return nil }
+func (s *memStorage) Purge(orgUUID uint64) (int, error)    { return 0, nil }
+
+func (s *memStorage) List(orgUUID uint64) ([]Subscription, error) {
+	return s.subs, nil
+}
+
+func (s *memStorage) AddDeadLetter(d DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadLetters = append(s.deadLetters, d)
+	return nil
+}
+
+func (s *memStorage) ListDeadLetters(orgUUID uint64) ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DeadLetter(nil), s.deadLetters...), nil
+}
+
+func TestRunMovesQueuedDeliveriesToDeadLetters(t *testing.T) {
+	storage := &memStorage{subs: []Subscription{{ID: 1, URL: "http://hooks.example.invalid/"}}}
+	d := NewDispatcher(slogdiscard.NewDiscardLogger(), storage, nil, Options{Workers: 1, MaxAttempts: 1})
+
+	change := event.Change{Type: event.ChangeCreated, Event: event.Event{UUID: 1}, At: time.Now()}
+	for range 3 {
+		d.OnChange(change)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx)
+
+	// после остановки новые изменения сразу попадают в dead-letter список
+	d.OnChange(change)
+
+	letters, _ := storage.ListDeadLetters(0)
+	if len(letters) != 4 {
+		t.Fatalf("got %d dead letters, want 4", len(letters))
+	}
+	if len(d.queue) != 0 {
+		t.Fatalf("%d deliveries left in the queue", len(d.queue))
+	}
+}
+
+// newTestDispatcher диспетчер с короткими задержками и клиентом, которому
+// разрешен адрес httptest-сервера
+func newTestDispatcher(storage *memStorage, maxAttempts int) *Dispatcher {
+	return NewDispatcher(slogdiscard.NewDiscardLogger(), storage, nil, Options{
+		Workers:     1,
+		MaxAttempts: maxAttempts,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+		Client:      Guard{AllowPrivate: true}.Client(time.Second),
+	})
+}
+
+// replyWith сервер отвечает статусами из codes по очереди, последний
+// повторяется для всех следующих запросов
+func replyWith(t *testing.T, calls *atomic.Int32, codes ...int) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		w.WriteHeader(codes[min(n, len(codes))-1])
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testDelivery(url string) delivery {
+	return delivery{
+		id:      "delivery-1",
+		sub:     Subscription{ID: 1, OrgUUID: 1, URL: url, Secret: "secret"},
+		typ:     event.ChangeCreated,
+		payload: []byte(`{"id":"delivery-1"}`),
+	}
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	storage := &memStorage{}
+	job := testDelivery(srv.URL)
+	newTestDispatcher(storage, 1).deliver(context.Background(), job)
+
+	if got := header.Get(HeaderSignature); got != Sign(job.sub.Secret, body) {
+		t.Fatalf("signature %q does not match the body", got)
+	}
+	if !Verify(job.sub.Secret, job.payload, header.Get(HeaderSignature)) {
+		t.Fatal("receiver got a body different from the payload")
+	}
+	if got := header.Get(HeaderEvent); got != "event.created" {
+		t.Fatalf("event header %q, want event.created", got)
+	}
+	if got := header.Get(HeaderDelivery); got != job.id {
+		t.Fatalf("delivery header %q, want %q", got, job.id)
+	}
+	if len(storage.deadLetters) != 0 {
+		t.Fatalf("delivered payload went to dead letters: %+v", storage.deadLetters)
+	}
+}
+
+func TestDeliverRetriesTransientStatuses(t *testing.T) {
+	for _, code := range []int{http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(code), func(t *testing.T) {
+			var calls atomic.Int32
+			srv := replyWith(t, &calls, code, code, http.StatusOK)
+
+			storage := &memStorage{}
+			newTestDispatcher(storage, 5).deliver(context.Background(), testDelivery(srv.URL))
+
+			if n := calls.Load(); n != 3 {
+				t.Fatalf("got %d attempts, want 3", n)
+			}
+			if len(storage.deadLetters) != 0 {
+				t.Fatalf("retried delivery went to dead letters: %+v", storage.deadLetters)
+			}
+		})
+	}
+}
+
+func TestDeliverDeadLettersClientErrors(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusGone} {
+		t.Run(http.StatusText(code), func(t *testing.T) {
+			var calls atomic.Int32
+			srv := replyWith(t, &calls, code)
+
+			storage := &memStorage{}
+			newTestDispatcher(storage, 5).deliver(context.Background(), testDelivery(srv.URL))
+
+			if n := calls.Load(); n != 1 {
+				t.Fatalf("got %d attempts, want 1", n)
+			}
+			if len(storage.deadLetters) != 1 || storage.deadLetters[0].Attempts != 1 {
+				t.Fatalf("got dead letters %+v, want one after the first attempt", storage.deadLetters)
+			}
+		})
+	}
+}
+
+func TestDeliverDeadLettersAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	srv := replyWith(t, &calls, http.StatusBadGateway)
+
+	storage := &memStorage{}
+	job := testDelivery(srv.URL)
+	newTestDispatcher(storage, 3).deliver(context.Background(), job)
+
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("got %d attempts, want 3", n)
+	}
+	if len(storage.deadLetters) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(storage.deadLetters))
+	}
+	dl := storage.deadLetters[0]
+	if dl.Attempts != 3 || dl.DeliveryID != job.id || dl.SubscriptionID != job.sub.ID {
+		t.Fatalf("unexpected dead letter %+v", dl)
+	}
+	if !strings.Contains(dl.LastError, "502") {
+		t.Fatalf("last error %q does not mention the status", dl.LastError)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	d := newTestDispatcher(&memStorage{}, 1)
+	d.opts.BaseBackoff = 10 * time.Millisecond
+	d.opts.MaxBackoff = 50 * time.Millisecond
+
+	// после 64-й попытки сдвиг переполняется, задержка все равно MaxBackoff
+	for attempt := 1; attempt <= 70; attempt++ {
+		want := d.opts.MaxBackoff
+		if attempt <= 3 {
+			want = d.opts.BaseBackoff << (attempt - 1)
+		}
+		got := d.backoff(attempt)
+		if got > want || got < want-want/5 {
+			t.Fatalf("attempt %d: backoff %v, want within 20%% below %v", attempt, got, want)
+		}
+	}
+}
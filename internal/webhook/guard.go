@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenHost = errors.New("webhook host resolves to a private or reserved address")
+
+// reserved сети, не покрытые методами netip.Addr: CGNAT, "эта" сеть,
+// IETF, бенчмарки и широковещательный адрес
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("255.255.255.255/32"),
+}
+
+// Guard не пускает вебхуки во внутренние сети сервиса: loopback, частные
+// диапазоны, link-local с адресом метаданных облака 169.254.169.254,
+// multicast и зарезервированные адреса. Нулевое значение проверяет адреса
+// через net.DefaultResolver
+type Guard struct {
+	// AllowPrivate отключает проверку, только для локальной разработки
+	AllowPrivate bool
+	Resolver     *net.Resolver
+}
+
+// CheckHost разрешает имя и отказывает, если хотя бы один адрес запрещен.
+// Проверка при подписке не защищает от смены DNS записи, поэтому адрес
+// проверяется еще раз при соединении, см. Client
+func (g Guard) CheckHost(ctx context.Context, host string) error {
+	if g.AllowPrivate {
+		return nil
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return checkAddr(ip)
+	}
+
+	r := g.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	ips, err := r.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %v", ErrInvalidURL, host, err)
+	}
+	for _, ip := range ips {
+		if err := checkAddr(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Client HTTP клиент доставки, который проверяет адрес каждого соединения
+// уже после разрешения имени, в том числе при редиректах. Прокси из
+// окружения не используется, иначе проверялся бы адрес прокси
+func (g Guard) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !g.AllowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			return checkAddr(ip)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func checkAddr(ip netip.Addr) error {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrForbiddenHost, ip)
+	}
+	for _, p := range reserved {
+		if p.Contains(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenHost, ip)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"calendar/internal/event"
+	"slices"
+	"time"
+)
+
+// Subscription подписка внешнего получателя на изменения событий.
+// Пустой Types означает подписку на все типы изменений
+type Subscription struct {
+	ID uint64
+	// OrgUUID подписка получает изменения только событий своей организации
+	OrgUUID uint64
+	// UserUUID владелец подписки: доставляются только события, которые он
+	// видит через API. 0 у подписок, созданных без аутентификации
+	UserUUID  uint64
+	URL       string
+	Secret    string
+	Types     []event.ChangeType
+	CreatedAt time.Time
+}
+
+func (s Subscription) Matches(t event.ChangeType) bool {
+	return len(s.Types) == 0 || slices.Contains(s.Types, t)
+}
+
+// DeadLetter доставка, которую не удалось выполнить за все попытки
+type DeadLetter struct {
+	DeliveryID     string
+	OrgUUID        uint64
+	SubscriptionID uint64
+	URL            string
+	Type           event.ChangeType
+	Payload        []byte
+	Attempts       int
+	LastError      string
+	FailedAt       time.Time
+}
+
+// Payload тело запроса, отправляемого получателю
+type Payload struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Event      event.Event `json:"event"`
+}
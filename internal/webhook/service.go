@@ -0,0 +1,105 @@
+// Package webhook provides подписки на изменения событий и их асинхронную
+// доставку подписанными HTTP запросами
+package webhook
+
+import (
+	"calendar/internal/auth"
+	"calendar/internal/event"
+	"calendar/internal/tenant"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("webhook not found")
+	ErrInvalidURL  = errors.New("webhook url must be absolute http(s) url")
+	ErrInvalidType = errors.New("unknown event type")
+)
+
+const secretBytes = 32
+
+// Service подписки организации из ctx, см. tenant.FromContext
+type Service interface {
+	Subscribe(ctx context.Context, s Subscription) (Subscription, error)
+	Unsubscribe(ctx context.Context, id uint64) error
+	List(ctx context.Context) ([]Subscription, error)
+	DeadLetters(ctx context.Context) ([]DeadLetter, error)
+	Purge(ctx context.Context) (int, error)
+}
+
+type service struct {
+	storage Storage
+	guard   Guard
+}
+
+// NewService guard проверяет адрес получателя при подписке
+func NewService(storage Storage, guard Guard) Service {
+	return &service{storage: storage, guard: guard}
+}
+
+// Subscribe сохраняет подписку, генерируя секрет если он не передан.
+// Секрет возвращается клиенту только в ответе на создание
+func (s *service) Subscribe(ctx context.Context, sub Subscription) (Subscription, error) {
+	const op = "webhook.subscribe"
+
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, fmt.Errorf("%s: %w: %q", op, ErrInvalidURL, sub.URL)
+	}
+	if err := s.guard.CheckHost(ctx, u.Hostname()); err != nil {
+		return Subscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, t := range sub.Types {
+		switch t {
+		case event.ChangeCreated, event.ChangeUpdated, event.ChangeDeleted:
+		default:
+			return Subscription{}, fmt.Errorf("%s: %w: %q", op, ErrInvalidType, t)
+		}
+	}
+	if sub.Secret == "" {
+		if sub.Secret, err = newSecret(); err != nil {
+			return Subscription{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	sub.CreatedAt = time.Now()
+	sub.OrgUUID = tenant.FromContext(ctx)
+	if id, ok := auth.FromContext(ctx); ok {
+		sub.UserUUID = id.UserUUID
+	}
+
+	id, err := s.storage.Add(sub)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+	sub.ID = id
+	return sub, nil
+}
+
+func (s *service) Unsubscribe(ctx context.Context, id uint64) error {
+	return s.storage.Delete(tenant.FromContext(ctx), id)
+}
+
+func (s *service) List(ctx context.Context) ([]Subscription, error) {
+	return s.storage.List(tenant.FromContext(ctx))
+}
+
+func (s *service) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	return s.storage.ListDeadLetters(tenant.FromContext(ctx))
+}
+
+func (s *service) Purge(ctx context.Context) (int, error) {
+	return s.storage.Purge(tenant.FromContext(ctx))
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
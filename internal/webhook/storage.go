@@ -0,0 +1,13 @@
+package webhook
+
+// Storage подписки и dead-letter записи, выборки ограничены организацией
+type Storage interface {
+	Add(s Subscription) (uint64, error)
+	Delete(orgUUID, id uint64) error
+	List(orgUUID uint64) ([]Subscription, error)
+	AddDeadLetter(d DeadLetter) error
+	ListDeadLetters(orgUUID uint64) ([]DeadLetter, error)
+	// Purge удаляет подписки и dead-letter записи организации и возвращает
+	// число удаленных подписок
+	Purge(orgUUID uint64) (int, error)
+}
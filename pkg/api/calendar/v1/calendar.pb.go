@@ -0,0 +1,724 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: calendar/v1/calendar.proto
+
+package calendarv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Reminder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Before        *durationpb.Duration   `protobuf:"bytes,1,opt,name=before,proto3" json:"before,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reminder) Reset() {
+	*x = Reminder{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reminder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reminder) ProtoMessage() {}
+
+func (x *Reminder) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reminder.ProtoReflect.Descriptor instead.
+func (*Reminder) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Reminder) GetBefore() *durationpb.Duration {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+type Event struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Uuid         uint64                 `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	UserUuid     uint64                 `protobuf:"varint,2,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`
+	CalendarUuid uint64                 `protobuf:"varint,3,opt,name=calendar_uuid,json=calendarUuid,proto3" json:"calendar_uuid,omitempty"`
+	Date         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	// Пустое значение означает событие длительностью по умолчанию.
+	End           *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=end,proto3" json:"end,omitempty"`
+	Title         string                 `protobuf:"bytes,6,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	Reminders     []*Reminder            `protobuf:"bytes,8,rep,name=reminders,proto3" json:"reminders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Event) GetUuid() uint64 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+func (x *Event) GetUserUuid() uint64 {
+	if x != nil {
+		return x.UserUuid
+	}
+	return 0
+}
+
+func (x *Event) GetCalendarUuid() uint64 {
+	if x != nil {
+		return x.CalendarUuid
+	}
+	return 0
+}
+
+func (x *Event) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *Event) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *Event) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Event) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Event) GetReminders() []*Reminder {
+	if x != nil {
+		return x.Reminders
+	}
+	return nil
+}
+
+type AddRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddRequest) Reset() {
+	*x = AddRequest{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRequest) ProtoMessage() {}
+
+func (x *AddRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRequest.ProtoReflect.Descriptor instead.
+func (*AddRequest) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddRequest) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type AddResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          uint64                 `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddResponse) Reset() {
+	*x = AddResponse{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddResponse) ProtoMessage() {}
+
+func (x *AddResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddResponse.ProtoReflect.Descriptor instead.
+func (*AddResponse) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AddResponse) GetUuid() uint64 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+type UpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateRequest) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type UpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateResponse) Reset() {
+	*x = UpdateResponse{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateResponse) ProtoMessage() {}
+
+func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{5}
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          uint64                 `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteRequest) GetUuid() uint64 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{7}
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          uint64                 `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetRequest) GetUuid() uint64 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type ListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Любой момент внутри нужного дня, недели или месяца.
+	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListRequest) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_calendar_v1_calendar_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calendar_v1_calendar_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_calendar_v1_calendar_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListResponse) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+var File_calendar_v1_calendar_proto protoreflect.FileDescriptor
+
+const file_calendar_v1_calendar_proto_rawDesc = "" +
+	"\n" +
+	"\x1acalendar/v1/calendar.proto\x12\vcalendar.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"=\n" +
+	"\bReminder\x121\n" +
+	"\x06before\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06before\"\xa8\x02\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x04R\x04uuid\x12\x1b\n" +
+	"\tuser_uuid\x18\x02 \x01(\x04R\buserUuid\x12#\n" +
+	"\rcalendar_uuid\x18\x03 \x01(\x04R\fcalendarUuid\x12.\n" +
+	"\x04date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12,\n" +
+	"\x03end\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x12\x14\n" +
+	"\x05title\x18\x06 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x123\n" +
+	"\treminders\x18\b \x03(\v2\x15.calendar.v1.ReminderR\treminders\"6\n" +
+	"\n" +
+	"AddRequest\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.calendar.v1.EventR\x05event\"!\n" +
+	"\vAddResponse\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x04R\x04uuid\"9\n" +
+	"\rUpdateRequest\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.calendar.v1.EventR\x05event\"\x10\n" +
+	"\x0eUpdateResponse\"#\n" +
+	"\rDeleteRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x04R\x04uuid\"\x10\n" +
+	"\x0eDeleteResponse\" \n" +
+	"\n" +
+	"GetRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x04R\x04uuid\"7\n" +
+	"\vGetResponse\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.calendar.v1.EventR\x05event\"=\n" +
+	"\vListRequest\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\":\n" +
+	"\fListResponse\x12*\n" +
+	"\x06events\x18\x01 \x03(\v2\x12.calendar.v1.EventR\x06events2\xd4\x03\n" +
+	"\x0fCalendarService\x128\n" +
+	"\x03Add\x12\x17.calendar.v1.AddRequest\x1a\x18.calendar.v1.AddResponse\x12A\n" +
+	"\x06Update\x12\x1a.calendar.v1.UpdateRequest\x1a\x1b.calendar.v1.UpdateResponse\x12A\n" +
+	"\x06Delete\x12\x1a.calendar.v1.DeleteRequest\x1a\x1b.calendar.v1.DeleteResponse\x128\n" +
+	"\x03Get\x12\x17.calendar.v1.GetRequest\x1a\x18.calendar.v1.GetResponse\x12@\n" +
+	"\tListByDay\x12\x18.calendar.v1.ListRequest\x1a\x19.calendar.v1.ListResponse\x12A\n" +
+	"\n" +
+	"ListByWeek\x12\x18.calendar.v1.ListRequest\x1a\x19.calendar.v1.ListResponse\x12B\n" +
+	"\vListByMonth\x12\x18.calendar.v1.ListRequest\x1a\x19.calendar.v1.ListResponseB)Z'calendar/pkg/api/calendar/v1;calendarv1b\x06proto3"
+
+var (
+	file_calendar_v1_calendar_proto_rawDescOnce sync.Once
+	file_calendar_v1_calendar_proto_rawDescData []byte
+)
+
+func file_calendar_v1_calendar_proto_rawDescGZIP() []byte {
+	file_calendar_v1_calendar_proto_rawDescOnce.Do(func() {
+		file_calendar_v1_calendar_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_calendar_v1_calendar_proto_rawDesc), len(file_calendar_v1_calendar_proto_rawDesc)))
+	})
+	return file_calendar_v1_calendar_proto_rawDescData
+}
+
+var file_calendar_v1_calendar_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_calendar_v1_calendar_proto_goTypes = []any{
+	(*Reminder)(nil),              // 0: calendar.v1.Reminder
+	(*Event)(nil),                 // 1: calendar.v1.Event
+	(*AddRequest)(nil),            // 2: calendar.v1.AddRequest
+	(*AddResponse)(nil),           // 3: calendar.v1.AddResponse
+	(*UpdateRequest)(nil),         // 4: calendar.v1.UpdateRequest
+	(*UpdateResponse)(nil),        // 5: calendar.v1.UpdateResponse
+	(*DeleteRequest)(nil),         // 6: calendar.v1.DeleteRequest
+	(*DeleteResponse)(nil),        // 7: calendar.v1.DeleteResponse
+	(*GetRequest)(nil),            // 8: calendar.v1.GetRequest
+	(*GetResponse)(nil),           // 9: calendar.v1.GetResponse
+	(*ListRequest)(nil),           // 10: calendar.v1.ListRequest
+	(*ListResponse)(nil),          // 11: calendar.v1.ListResponse
+	(*durationpb.Duration)(nil),   // 12: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_calendar_v1_calendar_proto_depIdxs = []int32{
+	12, // 0: calendar.v1.Reminder.before:type_name -> google.protobuf.Duration
+	13, // 1: calendar.v1.Event.date:type_name -> google.protobuf.Timestamp
+	13, // 2: calendar.v1.Event.end:type_name -> google.protobuf.Timestamp
+	0,  // 3: calendar.v1.Event.reminders:type_name -> calendar.v1.Reminder
+	1,  // 4: calendar.v1.AddRequest.event:type_name -> calendar.v1.Event
+	1,  // 5: calendar.v1.UpdateRequest.event:type_name -> calendar.v1.Event
+	1,  // 6: calendar.v1.GetResponse.event:type_name -> calendar.v1.Event
+	13, // 7: calendar.v1.ListRequest.date:type_name -> google.protobuf.Timestamp
+	1,  // 8: calendar.v1.ListResponse.events:type_name -> calendar.v1.Event
+	2,  // 9: calendar.v1.CalendarService.Add:input_type -> calendar.v1.AddRequest
+	4,  // 10: calendar.v1.CalendarService.Update:input_type -> calendar.v1.UpdateRequest
+	6,  // 11: calendar.v1.CalendarService.Delete:input_type -> calendar.v1.DeleteRequest
+	8,  // 12: calendar.v1.CalendarService.Get:input_type -> calendar.v1.GetRequest
+	10, // 13: calendar.v1.CalendarService.ListByDay:input_type -> calendar.v1.ListRequest
+	10, // 14: calendar.v1.CalendarService.ListByWeek:input_type -> calendar.v1.ListRequest
+	10, // 15: calendar.v1.CalendarService.ListByMonth:input_type -> calendar.v1.ListRequest
+	3,  // 16: calendar.v1.CalendarService.Add:output_type -> calendar.v1.AddResponse
+	5,  // 17: calendar.v1.CalendarService.Update:output_type -> calendar.v1.UpdateResponse
+	7,  // 18: calendar.v1.CalendarService.Delete:output_type -> calendar.v1.DeleteResponse
+	9,  // 19: calendar.v1.CalendarService.Get:output_type -> calendar.v1.GetResponse
+	11, // 20: calendar.v1.CalendarService.ListByDay:output_type -> calendar.v1.ListResponse
+	11, // 21: calendar.v1.CalendarService.ListByWeek:output_type -> calendar.v1.ListResponse
+	11, // 22: calendar.v1.CalendarService.ListByMonth:output_type -> calendar.v1.ListResponse
+	16, // [16:23] is the sub-list for method output_type
+	9,  // [9:16] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_calendar_v1_calendar_proto_init() }
+func file_calendar_v1_calendar_proto_init() {
+	if File_calendar_v1_calendar_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_calendar_v1_calendar_proto_rawDesc), len(file_calendar_v1_calendar_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_calendar_v1_calendar_proto_goTypes,
+		DependencyIndexes: file_calendar_v1_calendar_proto_depIdxs,
+		MessageInfos:      file_calendar_v1_calendar_proto_msgTypes,
+	}.Build()
+	File_calendar_v1_calendar_proto = out.File
+	file_calendar_v1_calendar_proto_goTypes = nil
+	file_calendar_v1_calendar_proto_depIdxs = nil
+}
@@ -0,0 +1,141 @@
+// Package ical provides минимальную сериализацию iCalendar (RFC 5545)
+package ical
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+
+	defaultProdID = "-//calendarPET//EN"
+	utcLayout     = "20060102T150405Z"
+	maxLineOctets = 75
+)
+
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FreeBusy компонент VFREEBUSY
+type FreeBusy struct {
+	UID      string
+	Attendee string
+	Start    time.Time
+	End      time.Time
+	Busy     []Period
+}
+
+// Event компонент VEVENT
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+}
+
+type Calendar struct {
+	ProdID   string
+	Name     string
+	Stamp    time.Time
+	Events   []Event
+	FreeBusy []FreeBusy
+}
+
+func (c Calendar) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	prodID := c.ProdID
+	if prodID == "" {
+		prodID = defaultProdID
+	}
+
+	line(&b, "BEGIN", "VCALENDAR")
+	line(&b, "VERSION", "2.0")
+	line(&b, "PRODID", prodID)
+	line(&b, "CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		line(&b, "X-WR-CALNAME", escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		line(&b, "BEGIN", "VEVENT")
+		line(&b, "UID", e.UID)
+		line(&b, "DTSTAMP", formatTime(stamp))
+		line(&b, "DTSTART", formatTime(e.Start))
+		line(&b, "DTEND", formatTime(e.End))
+		line(&b, "SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line(&b, "DESCRIPTION", escape(e.Description))
+		}
+		line(&b, "END", "VEVENT")
+	}
+
+	for _, fb := range c.FreeBusy {
+		line(&b, "BEGIN", "VFREEBUSY")
+		line(&b, "UID", fb.UID)
+		line(&b, "DTSTAMP", formatTime(stamp))
+		line(&b, "DTSTART", formatTime(fb.Start))
+		line(&b, "DTEND", formatTime(fb.End))
+		if fb.Attendee != "" {
+			line(&b, "ATTENDEE", fb.Attendee)
+		}
+		for _, p := range fb.Busy {
+			line(&b, "FREEBUSY;FBTYPE=BUSY", formatTime(p.Start)+"/"+formatTime(p.End))
+		}
+		line(&b, "END", "VFREEBUSY")
+	}
+
+	line(&b, "END", "VCALENDAR")
+
+	return b.WriteTo(w)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(utcLayout)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// line пишет строку контента, сворачивая её по 75 октетов
+// без разрыва многобайтовых символов
+func line(b *bytes.Buffer, name, value string) {
+	s := name + ":" + value
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// продолжение начинается с пробела, он тоже занимает октет
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,179 @@
+package validators
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Lang код языка сообщений, базовый тег BCP 47 без региона
+type Lang string
+
+const (
+	LangEN Lang = "en"
+	LangRU Lang = "ru"
+
+	// DefaultLang язык, если клиент не прислал Accept-Language
+	// или ни один из запрошенных не поддерживается
+	DefaultLang = LangRU
+)
+
+// fallbackKey сообщение для тегов, которых нет в каталоге
+const fallbackKey = "default"
+
+// Шаблоны сообщений: ключ тег валидатора, для тегов с размером через
+// точку добавляется вид поля (string, number, slice). В тексте {param}
+// заменяется параметром тега целиком, {1}, {2}, ... его словами
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[Lang]map[string]string{
+		LangEN: {
+			fallbackKey:        "Invalid value",
+			"required":         "This field is required",
+			"alphanum":         "Only latin letters and digits are allowed",
+			"oneof":            "Must be one of: {param}",
+			"url":              "Invalid URL",
+			"email":            "Invalid email address",
+			"gtfield":          "Must be greater than field {param}",
+			"required_without": "This field is required unless {param} is set",
+			"excluded_with":    "Must not be set together with {param}",
+			"min.string":       "Must be at least {param} characters long",
+			"min.number":       "Must be at least {param}",
+			"min.slice":        "Must contain at least {param} items",
+			"max.string":       "Must be at most {param} characters long",
+			"max.number":       "Must be at most {param}",
+			"max.slice":        "Must contain at most {param} items",
+			"len.string":       "Must be exactly {param} characters long",
+			"len.number":       "Must be equal to {param}",
+			"len.slice":        "Must contain exactly {param} items",
+			TagFuture:          "Must be in the future",
+			TagMaxDuration:     "Must be at most {2} after field {1}",
+			TagTimezone:        "Unknown IANA time zone",
+			TagNoOverlap:       "Ranges must not overlap",
+		},
+		LangRU: {
+			fallbackKey:        "Некорректное значение",
+			"required":         "Это поле обязательно",
+			"alphanum":         "Допустимы только латинские буквы и цифры",
+			"oneof":            "Введите валидное значение: {param}",
+			"url":              "Некорректный URL",
+			"email":            "Некорректный email",
+			"gtfield":          "Значение должно быть больше поля {param}",
+			"required_without": "Поле обязательно, если не задано {param}",
+			"excluded_with":    "Нельзя задавать вместе с {param}",
+			"min.string":       "Минимум {param} символов",
+			"min.number":       "Значение должно быть не меньше {param}",
+			"min.slice":        "Минимум {param} элементов",
+			"max.string":       "Максимум {param} символов",
+			"max.number":       "Значение должно быть не больше {param}",
+			"max.slice":        "Максимум {param} элементов",
+			"len.string":       "Ровно {param} символов",
+			"len.number":       "Значение должно быть равно {param}",
+			"len.slice":        "Ровно {param} элементов",
+			TagFuture:          "Время должно быть в будущем",
+			TagMaxDuration:     "Значение должно быть не позже чем через {2} после поля {1}",
+			TagTimezone:        "Неизвестный часовой пояс IANA",
+			TagNoOverlap:       "Интервалы не должны пересекаться",
+		},
+	}
+)
+
+// RegisterMessages добавляет язык или дополняет сообщения существующего.
+// Ключи как в каталоге выше
+func RegisterMessages(lang Lang, messages map[string]string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	m, ok := catalog[lang]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		catalog[lang] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+func supported(lang Lang) bool {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	_, ok := catalog[lang]
+	return ok
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language с учетом q-весов.
+// Регион отбрасывается: en-US соответствует en
+func Negotiate(acceptLanguage string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		candidates = append(candidates, candidate{lang: Lang(strings.ToLower(base)), q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if c.q > 0 && supported(c.lang) {
+			return c.lang
+		}
+	}
+	return DefaultLang
+}
+
+// Message текст ошибки поля на языке lang. Отсутствующие в языке ключи
+// берутся из DefaultLang, неизвестные теги получают общее сообщение
+func Message(fe validator.FieldError, lang Lang) string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	keys := []string{fe.Tag() + "." + kindOf(fe), fe.Tag(), fallbackKey}
+	for _, l := range []Lang{lang, DefaultLang} {
+		for _, k := range keys {
+			if tmpl, ok := catalog[l][k]; ok {
+				return paramReplacer(fe.Param()).Replace(tmpl)
+			}
+		}
+	}
+	return ""
+}
+
+func kindOf(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "slice"
+	default:
+		return "number"
+	}
+}
+
+func paramReplacer(param string) *strings.Replacer {
+	pairs := []string{"{param}", param}
+	for i, word := range strings.Fields(param) {
+		pairs = append(pairs, "{"+strconv.Itoa(i+1)+"}", word)
+	}
+	return strings.NewReplacer(pairs...)
+}
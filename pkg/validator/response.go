@@ -1,8 +1,7 @@
 package validators
 
 import (
-	"fmt"
-	"github.com/go-playground/validator"
+	"github.com/go-playground/validator/v10"
 )
 
 type ValidationResponse struct {
@@ -28,28 +27,18 @@ func OK() ValidationResponse {
 	}
 }
 
+// ValidationError ошибки валидации на языке по умолчанию
 func ValidationError(errs validator.ValidationErrors) ValidationResponse {
-	errorsMap := make(map[string]string)
+	return ValidationErrorLang(errs, DefaultLang)
+}
+
+// ValidationErrorLang ошибки валидации на языке lang. Ключи это пути
+// полей в JSON, например reminders[0].minutesBefore
+func ValidationErrorLang(errs validator.ValidationErrors, lang Lang) ValidationResponse {
+	errorsMap := make(map[string]string, len(errs))
 
 	for _, err := range errs {
-		fieldName := err.Field()
-		tag := err.Tag()
-		param := err.Param()
-
-		var message string
-
-		switch tag {
-		case "required":
-			message = "Это поле обязательно"
-		case "alphanum":
-			message = "Допустимы только латинские буквы и цифры"
-		case "min":
-			message = fmt.Sprintf("Минимум %s символов", param)
-		case "oneof":
-			message = fmt.Sprintf("Ввидите валидное значение: %s", param)
-		}
-
-		errorsMap[fieldName] = message
+		errorsMap[fieldPath(err)] = Message(err, lang)
 	}
 
 	return ValidationResponse{
@@ -0,0 +1,128 @@
+package validators
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Теги календарных правил, регистрируются в общем экземпляре валидатора
+const (
+	// TagFuture время должно быть позже текущего момента
+	TagFuture = "future"
+	// TagMaxDuration ограничивает длину диапазона: maxduration=From 720h
+	// означает, что поле не дальше 720h от поля From той же структуры
+	TagMaxDuration = "maxduration"
+	// TagTimezone имя часового пояса из базы IANA, например Europe/Moscow
+	TagTimezone = "timezone"
+	// TagNoOverlap элементы среза, реализующие Ranger, не пересекаются
+	TagNoOverlap = "nooverlap"
+)
+
+// Ranger полуинтервал [start, end), который проверяет тег nooverlap
+type Ranger interface {
+	Range() (start, end time.Time)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func registerRules(v *validator.Validate) {
+	rules := map[string]validator.Func{
+		TagFuture:      isFuture,
+		TagMaxDuration: hasMaxDuration,
+		TagTimezone:    isTimezone,
+		TagNoOverlap:   hasNoOverlap,
+	}
+	for tag, fn := range rules {
+		// ошибка возможна только при пустом теге или nil функции
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func isFuture(fl validator.FieldLevel) bool {
+	t, ok := asTime(fl.Field())
+	return ok && t.After(time.Now())
+}
+
+func hasMaxDuration(fl validator.FieldLevel) bool {
+	name, raw, ok := strings.Cut(fl.Param(), " ")
+	if !ok {
+		panic("validator: maxduration expects \"<field> <duration>\", got " + fl.Param())
+	}
+	limit, err := time.ParseDuration(raw)
+	if err != nil {
+		panic("validator: maxduration: " + err.Error())
+	}
+
+	end, ok := asTime(fl.Field())
+	if !ok {
+		return false
+	}
+	parent := fl.Parent()
+	if parent.Kind() == reflect.Pointer {
+		parent = parent.Elem()
+	}
+	start, ok := asTime(parent.FieldByName(name))
+	if !ok {
+		return false
+	}
+	return end.Sub(start) <= limit
+}
+
+func isTimezone(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+	name := fl.Field().String()
+	// LoadLocation принимает "" и "Local" как UTC и зону сервера,
+	// клиенту они ничего не говорят
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+func hasNoOverlap(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.Slice && field.Kind() != reflect.Array {
+		return false
+	}
+
+	type span struct{ start, end time.Time }
+	spans := make([]span, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		r, ok := field.Index(i).Interface().(Ranger)
+		if !ok {
+			return false
+		}
+		start, end := r.Range()
+		spans = append(spans, span{start, end})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start.Before(spans[i-1].end) {
+			return false
+		}
+	}
+	return true
+}
+
+func asTime(v reflect.Value) (time.Time, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return time.Time{}, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Type() != timeType {
+		return time.Time{}, false
+	}
+	return v.Interface().(time.Time), true
+}
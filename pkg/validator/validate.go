@@ -0,0 +1,43 @@
+package validators
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate общий экземпляр: validator.Validate безопасен для параллельного
+// использования и кеширует разбор структур, поэтому создается один раз
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	// в ошибках поля называются так же, как в JSON
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return f.Name
+		}
+		return name
+	})
+	registerRules(v)
+	return v
+}
+
+// Struct проверяет структуру по тегам validate
+func Struct(s any) error {
+	return validate.Struct(s)
+}
+
+// fieldPath путь к полю от корня запроса, например reminders[0].minutesBefore
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, path, ok := strings.Cut(ns, "."); ok {
+		return path
+	}
+	return fe.Field()
+}